@@ -5,7 +5,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"github.com/segmentio/kafka-go"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"log"
@@ -13,10 +12,19 @@ import (
 	"os"
 	"os/signal"
 	"realtime-ranking/consumer"
+	"realtime-ranking/eventbus"
+	"realtime-ranking/events"
 	"realtime-ranking/handlers"
+	"realtime-ranking/handlers/admin"
+	"realtime-ranking/handlers/creators"
 	"realtime-ranking/handlers/videos"
+	"realtime-ranking/lifecycle"
+	"realtime-ranking/migrations"
+	"realtime-ranking/outbox"
+	"realtime-ranking/scoring"
 	"realtime-ranking/services"
 	"realtime-ranking/store"
+	"strconv"
 	"syscall"
 	"time"
 	_ "realtime-ranking/docs"
@@ -27,6 +35,10 @@ import (
 // @description API for managing and retrieving real-time video rankings.
 // @host        localhost:8080
 // @BasePath    /
+// @securityDefinitions.apikey AdminToken
+// @in          header
+// @name        Authorization
+// @description Admin endpoints require "Bearer <ADMIN_TOKEN>".
 func main() {
 	postgresURL := os.Getenv("POSTGRES_URL")
 	if postgresURL == "" {
@@ -44,44 +56,200 @@ func main() {
 	}
 	kafkaBrokers := []string{kafkaBrokersRaw}
 
-	// Use pgxpool for connection pooling
-	pgConfig, err := pgxpool.ParseConfig(postgresURL)
+	// Subcommands only need the connection settings, so they are dispatched before the
+	// server's own settings are parsed and cannot be blocked by them.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		pgPool := connectPostgres(postgresURL)
+		defer pgPool.Close()
+		if err := runMigrateCommand(context.Background(), pgPool, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	eventBusKind := os.Getenv("EVENT_BUS")
+	if eventBusKind == "" {
+		eventBusKind = "kafka"
+	}
+	bus, err := eventbus.New(eventBusKind, kafkaBrokers)
 	if err != nil {
-		log.Fatalf("Failed to parse Postgres URL: %v", err)
+		log.Fatalf("Invalid EVENT_BUS: %v", err)
 	}
-	pgPool, err := pgxpool.ConnectConfig(context.Background(), pgConfig)
+	if eventBusKind == "memory" {
+		log.Println("Using in-memory event bus; events will not survive a restart")
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "redrive-dlq" {
+		if err := runRedriveCommand(context.Background(), bus, os.Args[2:]); err != nil {
+			log.Fatalf("Re-drive failed: %v", err)
+		}
+		return
+	}
+
+	hotHalfLife := store.DefaultHotHalfLife
+	if hotHalfLifeRaw := os.Getenv("HOT_HALF_LIFE"); hotHalfLifeRaw != "" {
+		parsed, err := time.ParseDuration(hotHalfLifeRaw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid HOT_HALF_LIFE %q: must be a positive duration such as 6h", hotHalfLifeRaw)
+		}
+		hotHalfLife = parsed
+	}
+
+	eventDedupeTTL := handlers.DefaultEventDedupeTTL
+	if eventDedupeTTLRaw := os.Getenv("EVENT_DEDUPE_TTL"); eventDedupeTTLRaw != "" {
+		parsed, err := time.ParseDuration(eventDedupeTTLRaw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid EVENT_DEDUPE_TTL %q: must be a positive duration such as 24h", eventDedupeTTLRaw)
+		}
+		eventDedupeTTL = parsed
+	}
+
+	shutdownTimeout := 30 * time.Second
+	if shutdownTimeoutRaw := os.Getenv("SHUTDOWN_TIMEOUT"); shutdownTimeoutRaw != "" {
+		parsed, err := time.ParseDuration(shutdownTimeoutRaw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid SHUTDOWN_TIMEOUT %q: must be a positive duration such as 30s", shutdownTimeoutRaw)
+		}
+		shutdownTimeout = parsed
+	}
+
+	consumerConfig := consumer.Config{
+		Workers:     consumer.DefaultWorkers,
+		BatchWindow: consumer.DefaultBatchWindow,
+		BatchSize:   consumer.DefaultBatchSize,
+	}
+	if consumerWorkersRaw := os.Getenv("CONSUMER_WORKERS"); consumerWorkersRaw != "" {
+		parsed, err := strconv.Atoi(consumerWorkersRaw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid CONSUMER_WORKERS %q: must be a positive integer", consumerWorkersRaw)
+		}
+		consumerConfig.Workers = parsed
+	}
+	if batchWindowRaw := os.Getenv("EVENT_BATCH_WINDOW"); batchWindowRaw != "" {
+		parsed, err := time.ParseDuration(batchWindowRaw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid EVENT_BATCH_WINDOW %q: must be a positive duration such as 200ms", batchWindowRaw)
+		}
+		consumerConfig.BatchWindow = parsed
+	}
+	if batchSizeRaw := os.Getenv("EVENT_BATCH_SIZE"); batchSizeRaw != "" {
+		parsed, err := strconv.Atoi(batchSizeRaw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid EVENT_BATCH_SIZE %q: must be a positive integer", batchSizeRaw)
+		}
+		consumerConfig.BatchSize = parsed
+	}
+
+	adminToken := os.Getenv("ADMIN_TOKEN")
+
+	scoringProvider, err := scoring.NewProvider(os.Getenv("SCORING_CONFIG_FILE"))
 	if err != nil {
-		log.Fatalf("Failed to connect to Postgres: %v", err)
+		log.Fatalf("Failed to load scoring config: %v", err)
+	}
+	log.Printf("Using scoring config version %s", scoringProvider.Current().Version)
+
+	leaderboardConfig := services.LeaderboardConfig{
+		AudienceMinVideos: services.DefaultAudienceMinVideos,
+	}
+	if tagLeaderboardsRaw := os.Getenv("TAG_LEADERBOARDS"); tagLeaderboardsRaw != "" {
+		parsed, err := strconv.ParseBool(tagLeaderboardsRaw)
+		if err != nil {
+			log.Fatalf("Invalid TAG_LEADERBOARDS %q: must be true or false", tagLeaderboardsRaw)
+		}
+		leaderboardConfig.TagLeaderboards = parsed
+	}
+	if creatorTopKRaw := os.Getenv("CREATOR_TOP_K"); creatorTopKRaw != "" {
+		parsed, err := strconv.Atoi(creatorTopKRaw)
+		if err != nil || parsed < 0 {
+			log.Fatalf("Invalid CREATOR_TOP_K %q: must be a non-negative integer", creatorTopKRaw)
+		}
+		leaderboardConfig.CreatorTopK = parsed
+	}
+	if audienceMinVideosRaw := os.Getenv("AUDIENCE_MIN_VIDEOS"); audienceMinVideosRaw != "" {
+		parsed, err := strconv.Atoi(audienceMinVideosRaw)
+		if err != nil || parsed < 0 {
+			log.Fatalf("Invalid AUDIENCE_MIN_VIDEOS %q: must be a non-negative integer", audienceMinVideosRaw)
+		}
+		leaderboardConfig.AudienceMinVideos = parsed
 	}
-	defer pgPool.Close()
 
-	redisStore, err := store.NewRedisStore(redisURL)
+	eventPublishMode := os.Getenv("EVENT_PUBLISH_MODE")
+	if eventPublishMode == "" {
+		eventPublishMode = "kafka"
+	}
+
+	eventFormat, err := events.ParseFormat(os.Getenv("EVENT_ENCODING"))
 	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
-	}
-	defer redisStore.Close()
-
-	kafkaWriter := &kafka.Writer{
-		Addr:     kafka.TCP(kafkaBrokers...),
-		Topic:    "video-events",
-		Balancer: &kafka.LeastBytes{},
-		// Configure for better performance and reliability
-		WriteTimeout:   10 * time.Second,
-		ReadTimeout:    10 * time.Second,
-		RequiredAcks:   kafka.RequireOne,
-		MaxAttempts:    3,
-		Async:          true, // Enable asynchronous writes
-		Completion:     nil,  // Handle completion callbacks if needed
-		BatchSize:      100,
-		BatchTimeout:   time.Millisecond * 10,
-		//Linger:         time.Millisecond * 5,
-		//ReadBatchTimeout: time.Millisecond * 10,
-	}
-	defer kafkaWriter.Close()
-
-	postgresStore := store.NewPostgresStore(pgPool)
-	rankingService := services.NewRankingService(redisStore, postgresStore, kafkaWriter)
-	videoEventHandler := handlers.NewVideoEventHandler(rankingService)
+		log.Fatalf("Invalid EVENT_ENCODING: %v", err)
+	}
+
+	ingestMode := os.Getenv("INGEST_MODE")
+	if ingestMode == "" {
+		ingestMode = "async"
+	}
+	if ingestMode != "async" && ingestMode != "direct" {
+		log.Fatalf("Unknown INGEST_MODE %q: expected async or direct", ingestMode)
+	}
+	if ingestMode == "direct" && eventPublishMode == "outbox" {
+		log.Fatalf("EVENT_PUBLISH_MODE=outbox cannot be combined with INGEST_MODE=direct")
+	}
+
+	storeBackend := os.Getenv("STORE_BACKEND")
+	if storeBackend == "" {
+		storeBackend = "postgres"
+	}
+
+	var repository store.VideoRepository
+	var rankingIndex store.RankingIndex
+	var outboxStore store.EventOutbox
+	switch storeBackend {
+	case "memory":
+		log.Println("Using in-memory store; data will not survive a restart")
+		memoryStore := store.NewMemoryStore(hotHalfLife)
+		repository, rankingIndex, outboxStore = memoryStore, memoryStore, memoryStore
+	case "postgres":
+		pgPool := connectPostgres(postgresURL)
+
+		migrator, err := migrations.NewMigrator(pgPool)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to apply migrations: %v", err)
+		}
+		log.Printf("Applied %d database migration(s)", applied)
+
+		redisStore, err := store.NewRedisStore(redisURL, hotHalfLife)
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		postgresStore := store.NewPostgresStore(pgPool)
+		repository, rankingIndex, outboxStore = postgresStore, redisStore, postgresStore
+	default:
+		log.Fatalf("Unknown STORE_BACKEND %q: expected postgres or memory", storeBackend)
+	}
+
+	eventPublisher := bus.Publisher(eventbus.PublisherConfig{Async: true})
+
+	var eventOutbox store.EventOutbox
+	var outboxRelay *outbox.Relay
+	switch eventPublishMode {
+	case "kafka":
+	case "outbox":
+		log.Println("Publishing events through the outbox")
+		eventOutbox = outboxStore
+		outboxRelay = outbox.NewRelay(outboxStore, bus.Publisher(eventbus.PublisherConfig{}))
+		go outboxRelay.Run()
+	default:
+		log.Fatalf("Unknown EVENT_PUBLISH_MODE %q: expected kafka or outbox", eventPublishMode)
+	}
+
+	rankingService := services.NewRankingService(repository, rankingIndex, eventPublisher, eventOutbox, eventFormat, scoringProvider, leaderboardConfig)
+	// Videos last scored under another config version are brought up to date.
+	rankingService.StartRescore()
+	videoEventHandler := handlers.NewVideoEventHandler(rankingService, eventDedupeTTL)
+	videoEventConsumer := consumer.NewVideoEventConsumer(bus, videoEventHandler, consumerConfig)
 
 	router := gin.Default()
 
@@ -90,19 +258,50 @@ func main() {
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	videoHandler := videos.NewVideoHandler(rankingService, validate)
+	// In direct mode the endpoints apply events themselves, so the consumer is not run.
+	var directEventHandler *handlers.VideoEventHandler
+	if ingestMode == "direct" {
+		log.Println("Applying events inline; the event consumer is not started")
+		directEventHandler = videoEventHandler
+	}
+	videoHandler := videos.NewVideoHandler(rankingService, validate, directEventHandler)
 	router.POST("/videos", videoHandler.CreateVideo)
 	router.PUT("/videos/:id", videoHandler.UpdateVideo)
+	router.PATCH("/videos/:id", videoHandler.UpdateVideo)
 	router.POST("/videos/:id/view", videoHandler.HandleView)
 	router.POST("/videos/:id/like", videoHandler.HandleLike)
 	router.POST("/videos/:id/comment", videoHandler.HandleComment)
 	router.POST("/videos/:id/share", videoHandler.HandleShare)
 	router.POST("/videos/:id/watch", videoHandler.HandleWatch)
 	router.GET("/videos/top", videoHandler.GetTopVideos)
+	router.GET("/videos/:id/rank", videoHandler.GetVideoRank)
+	router.GET("/videos/:id/neighbors", videoHandler.GetVideoNeighbors)
+	router.GET("/categories/videos/top", videoHandler.GetTopVideosInCategories)
+	router.GET("/categories/:name/videos/top", videoHandler.GetTopVideosInCategory)
+	if leaderboardConfig.TagLeaderboards {
+		router.GET("/tags/videos/top", videoHandler.GetTopVideosWithTags)
+		router.GET("/tags/:name/videos/top", videoHandler.GetTopVideosWithTag)
+	}
 	router.GET("/users/:userID/videos/top", videoHandler.GetTopVideosPerUser)
 	router.POST("/users/:userID/preferences", videoHandler.UpdateUserPreferences)
 
-	go consumer.ConsumeVideoEvents(kafkaBrokers, videoEventHandler)
+	creatorHandler := creators.NewCreatorHandler(rankingService)
+	router.GET("/creators/top", creatorHandler.GetTopCreators)
+	router.GET("/creators/:id/videos/top", creatorHandler.GetTopCreatorVideos)
+
+	if adminToken != "" {
+		adminHandler := admin.NewAdminHandler(rankingService, videoEventConsumer)
+		adminRoutes := router.Group("/admin", admin.RequireToken(adminToken))
+		adminRoutes.GET("/scoring", adminHandler.GetScoringConfig)
+		adminRoutes.POST("/scoring/reload", adminHandler.ReloadScoringConfig)
+		adminRoutes.GET("/consumer/workers", adminHandler.GetConsumerWorkers)
+	} else {
+		log.Println("ADMIN_TOKEN is not set; admin endpoints are disabled")
+	}
+
+	if ingestMode == "async" {
+		go videoEventConsumer.Run(context.Background())
+	}
 
 	srv := &http.Server{
 		Addr:    ":8080",
@@ -119,17 +318,45 @@ func main() {
 		}
 	}()
 
+	// Stop accepting events first, then drain the consumer, then flush the producer,
+	// and close the stores last since everything before writes to them.
+	lifecycleManager := lifecycle.NewManager()
+	lifecycleManager.OnShutdown("HTTP server", srv.Shutdown)
+	if outboxRelay != nil {
+		lifecycleManager.OnShutdown("outbox relay", outboxRelay.Stop)
+	}
+	if ingestMode == "async" {
+		lifecycleManager.OnShutdown("event consumer", videoEventConsumer.Stop)
+	}
+	lifecycleManager.OnShutdownClose("event publisher", eventPublisher.Close)
+	lifecycleManager.OnShutdownClose("event bus", bus.Close)
+	lifecycleManager.OnShutdownClose("ranking index", rankingIndex.Close)
+	lifecycleManager.OnShutdownClose("repository", repository.Close)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	log.Printf("Shutting down server (deadline %v)...", shutdownTimeout)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := lifecycleManager.Shutdown(ctx); err != nil {
 		log.Fatalf("Server shutdown failed: %v", err)
 	}
 
 	log.Println("Server exited")
-}
\ No newline at end of file
+}
+
+// connectPostgres opens a pgxpool connection pool for the given URL.
+func connectPostgres(postgresURL string) *pgxpool.Pool {
+	pgConfig, err := pgxpool.ParseConfig(postgresURL)
+	if err != nil {
+		log.Fatalf("Failed to parse Postgres URL: %v", err)
+	}
+	pgPool, err := pgxpool.ConnectConfig(context.Background(), pgConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to Postgres: %v", err)
+	}
+	return pgPool
+}
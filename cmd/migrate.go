@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"realtime-ranking/migrations"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// runMigrateCommand handles `realtime-ranking migrate up|down|status`.
+func runMigrateCommand(ctx context.Context, pool *pgxpool.Pool, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: migrate up|down|status")
+	}
+
+	migrator, err := migrations.NewMigrator(pool)
+	if err != nil {
+		return fmt.Errorf("error loading migrations: %w", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			fmt.Println("No migrations to roll back")
+			return nil
+		}
+		fmt.Printf("Rolled back %d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q: expected up, down or status", args[0])
+	}
+	return nil
+}
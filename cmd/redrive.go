@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"realtime-ranking/consumer"
+	"realtime-ranking/eventbus"
+)
+
+// runRedriveCommand handles `realtime-ranking redrive-dlq [-limit N]`.
+func runRedriveCommand(ctx context.Context, bus eventbus.Bus, args []string) error {
+	flags := flag.NewFlagSet("redrive-dlq", flag.ContinueOnError)
+	limit := flags.Int("limit", 0, "maximum number of messages to re-drive (0 re-drives all)")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("usage: redrive-dlq [-limit N]")
+	}
+
+	redriven, err := consumer.RedriveDeadLetters(ctx, bus, *limit)
+	fmt.Printf("Re-drove %d message(s) from %s to %s\n", redriven, consumer.DeadLetterTopic, consumer.VideoEventsTopic)
+	return err
+}
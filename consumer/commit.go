@@ -0,0 +1,131 @@
+package consumer
+
+import (
+	"context"
+	"log"
+	"realtime-ranking/eventbus"
+	"sync"
+	"time"
+)
+
+// Offsets are committed in batches: every commitInterval, or sooner once
+// commitBatchSize messages have completed since the last commit.
+const (
+	commitBatchSize = 100
+	commitInterval  = time.Second
+)
+
+// offsetCommitter commits offsets for messages that have been fully processed. Workers
+// finish messages out of order, so for each partition it only commits up to the
+// highest offset below which every fetched message is done. Delivery is
+// at-least-once: after a crash, messages since the last commit are read again and
+// rely on event de-duplication.
+type offsetCommitter struct {
+	subscriber eventbus.Subscriber
+	kick       chan struct{}
+
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+	completed  int
+}
+
+type partitionOffsets struct {
+	// inFlight holds fetched messages in offset order until they can be committed.
+	inFlight []eventbus.Message
+	done     map[int64]bool
+	// committable is the latest message whose offset, and every one before it, is done.
+	committable *eventbus.Message
+}
+
+func newOffsetCommitter(subscriber eventbus.Subscriber) *offsetCommitter {
+	return &offsetCommitter{
+		subscriber: subscriber,
+		kick:       make(chan struct{}, 1),
+		partitions: make(map[int]*partitionOffsets),
+	}
+}
+
+// track records a fetched message; it must be called in fetch order.
+func (oc *offsetCommitter) track(msg eventbus.Message) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	partition, exists := oc.partitions[msg.Partition]
+	if !exists {
+		partition = &partitionOffsets{done: make(map[int64]bool)}
+		oc.partitions[msg.Partition] = partition
+	}
+	partition.inFlight = append(partition.inFlight, msg)
+}
+
+// markDone records that msg has been processed.
+func (oc *offsetCommitter) markDone(msg eventbus.Message) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	partition := oc.partitions[msg.Partition]
+	partition.done[msg.Offset] = true
+	for len(partition.inFlight) > 0 && partition.done[partition.inFlight[0].Offset] {
+		head := partition.inFlight[0]
+		delete(partition.done, head.Offset)
+		partition.committable = &head
+		partition.inFlight = partition.inFlight[1:]
+	}
+
+	oc.completed++
+	if oc.completed >= commitBatchSize {
+		select {
+		case oc.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run commits periodically until ctx is cancelled, then makes a final commit.
+func (oc *offsetCommitter) run(ctx context.Context) {
+	ticker := time.NewTicker(commitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			oc.commit(context.Background())
+			return
+		case <-ticker.C:
+		case <-oc.kick:
+		}
+		oc.commit(ctx)
+	}
+}
+
+// commit commits the committable offset of every partition. On failure they are kept
+// and retried with the next commit unless a later offset has become committable.
+func (oc *offsetCommitter) commit(ctx context.Context) {
+	oc.mu.Lock()
+	var messages []eventbus.Message
+	for _, partition := range oc.partitions {
+		if partition.committable != nil {
+			messages = append(messages, *partition.committable)
+			partition.committable = nil
+		}
+	}
+	oc.completed = 0
+	oc.mu.Unlock()
+
+	if len(messages) == 0 {
+		return
+	}
+	if err := oc.subscriber.Commit(ctx, messages...); err != nil {
+		log.Printf("Error committing offsets for %d partition(s): %v", len(messages), err)
+
+		oc.mu.Lock()
+		for _, msg := range messages {
+			partition := oc.partitions[msg.Partition]
+			if partition.committable == nil {
+				msg := msg
+				partition.committable = &msg
+			}
+		}
+		oc.mu.Unlock()
+	}
+}
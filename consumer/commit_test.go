@@ -0,0 +1,176 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"realtime-ranking/eventbus"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// recordingSubscriber records the offsets committed per partition and optionally
+// fails commits.
+type recordingSubscriber struct {
+	committed map[int][]int64
+	err       error
+}
+
+func newRecordingSubscriber() *recordingSubscriber {
+	return &recordingSubscriber{committed: make(map[int][]int64)}
+}
+
+func (rs *recordingSubscriber) Fetch(ctx context.Context) (eventbus.Message, error) {
+	<-ctx.Done()
+	return eventbus.Message{}, ctx.Err()
+}
+
+func (rs *recordingSubscriber) Commit(ctx context.Context, messages ...eventbus.Message) error {
+	if rs.err != nil {
+		return rs.err
+	}
+	for _, msg := range messages {
+		rs.committed[msg.Partition] = append(rs.committed[msg.Partition], msg.Offset)
+	}
+	return nil
+}
+
+func (rs *recordingSubscriber) Close() error {
+	return nil
+}
+
+// offset identifies a message by partition and offset.
+type offset struct {
+	partition int
+	offset    int64
+}
+
+func TestOffsetCommitterCommitsContiguousOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		fetched []offset
+		// done lists completed messages in completion order. A nil entry commits.
+		done []*offset
+		// want holds the offsets committed per partition, in commit order.
+		want map[int][]int64
+	}{
+		{
+			name:    "nothing done",
+			fetched: []offset{{0, 10}, {0, 11}},
+			done:    []*offset{nil},
+			want:    map[int][]int64{},
+		},
+		{
+			name:    "in order",
+			fetched: []offset{{0, 10}, {0, 11}, {0, 12}},
+			done:    []*offset{{0, 10}, {0, 11}, {0, 12}, nil},
+			want:    map[int][]int64{0: {12}},
+		},
+		{
+			name:    "later offsets done first wait for the earliest",
+			fetched: []offset{{0, 10}, {0, 11}, {0, 12}},
+			done:    []*offset{{0, 12}, {0, 11}, nil},
+			want:    map[int][]int64{},
+		},
+		{
+			name:    "earliest done last releases every offset",
+			fetched: []offset{{0, 10}, {0, 11}, {0, 12}},
+			done:    []*offset{{0, 12}, {0, 11}, nil, {0, 10}, nil},
+			want:    map[int][]int64{0: {12}},
+		},
+		{
+			name:    "gap stops the commit",
+			fetched: []offset{{0, 10}, {0, 11}, {0, 12}, {0, 13}},
+			done:    []*offset{{0, 10}, {0, 13}, {0, 11}, nil},
+			want:    map[int][]int64{0: {11}},
+		},
+		{
+			name:    "gap filled after a commit",
+			fetched: []offset{{0, 10}, {0, 11}, {0, 12}, {0, 13}},
+			done:    []*offset{{0, 10}, {0, 13}, {0, 11}, nil, {0, 12}, nil},
+			want:    map[int][]int64{0: {11, 13}},
+		},
+		{
+			name:    "non-consecutive offsets",
+			fetched: []offset{{0, 10}, {0, 15}, {0, 40}},
+			done:    []*offset{{0, 15}, {0, 10}, nil},
+			want:    map[int][]int64{0: {15}},
+		},
+		{
+			name:    "nothing new to commit",
+			fetched: []offset{{0, 10}, {0, 11}},
+			done:    []*offset{{0, 10}, nil, nil},
+			want:    map[int][]int64{0: {10}},
+		},
+		{
+			name:    "partitions are independent",
+			fetched: []offset{{0, 10}, {1, 20}, {0, 11}, {1, 21}, {2, 30}},
+			done:    []*offset{{1, 21}, {0, 10}, {2, 30}, nil, {1, 20}, nil},
+			want:    map[int][]int64{0: {10}, 1: {21}, 2: {30}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriber := newRecordingSubscriber()
+			committer := newOffsetCommitter(subscriber)
+			for _, fetched := range tt.fetched {
+				committer.track(eventbus.Message{Partition: fetched.partition, Offset: fetched.offset})
+			}
+			for _, done := range tt.done {
+				if done == nil {
+					committer.commit(context.Background())
+					continue
+				}
+				committer.markDone(eventbus.Message{Partition: done.partition, Offset: done.offset})
+			}
+
+			if !reflect.DeepEqual(subscriber.committed, tt.want) {
+				t.Errorf("committed %v, want %v", sortedCommits(subscriber.committed), sortedCommits(tt.want))
+			}
+		})
+	}
+}
+
+func TestOffsetCommitterRetriesFailedCommit(t *testing.T) {
+	subscriber := newRecordingSubscriber()
+	committer := newOffsetCommitter(subscriber)
+	for _, msgOffset := range []int64{10, 11, 12} {
+		committer.track(eventbus.Message{Offset: msgOffset})
+	}
+
+	committer.markDone(eventbus.Message{Offset: 10})
+	subscriber.err = errors.New("broker unavailable")
+	committer.commit(context.Background())
+
+	// The failed offset is committed next time if nothing later has become committable.
+	subscriber.err = nil
+	committer.commit(context.Background())
+	if want := map[int][]int64{0: {10}}; !reflect.DeepEqual(subscriber.committed, want) {
+		t.Fatalf("committed %v, want %v", subscriber.committed, want)
+	}
+
+	// A failed offset is superseded by a later one.
+	committer.markDone(eventbus.Message{Offset: 11})
+	subscriber.err = errors.New("broker unavailable")
+	committer.commit(context.Background())
+	committer.markDone(eventbus.Message{Offset: 12})
+	subscriber.err = nil
+	committer.commit(context.Background())
+	if want := map[int][]int64{0: {10, 12}}; !reflect.DeepEqual(subscriber.committed, want) {
+		t.Errorf("committed %v, want %v", subscriber.committed, want)
+	}
+}
+
+// sortedCommits formats commits in partition order for failure messages.
+func sortedCommits(committed map[int][]int64) [][]int64 {
+	partitions := make([]int, 0, len(committed))
+	for partition := range committed {
+		partitions = append(partitions, partition)
+	}
+	sort.Ints(partitions)
+	commits := make([][]int64, 0, len(partitions))
+	for _, partition := range partitions {
+		commits = append(commits, append([]int64{int64(partition)}, committed[partition]...))
+	}
+	return commits
+}
@@ -2,48 +2,224 @@ package consumer
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
+	"realtime-ranking/eventbus"
 	"realtime-ranking/handlers"
 	"realtime-ranking/models"
+	"realtime-ranking/services"
+	"sync"
 	"time"
+)
+
+const (
+	VideoEventsTopic = services.VideoEventsTopic
+	DeadLetterTopic  = "video-events-dlq"
+)
+
+// Retry policy for transient processing and fetch errors. Attempts back off
+// exponentially from retryBackoffMin up to retryBackoffMax.
+const (
+	retryBackoffMin = 100 * time.Millisecond
+	retryBackoffMax = 5 * time.Second
+)
 
-	"github.com/segmentio/kafka-go"
+// Defaults used when Config leaves a field unset.
+const (
+	DefaultWorkers     = 4
+	DefaultBatchWindow = 200 * time.Millisecond
+	DefaultBatchSize   = 500
 )
 
-func ConsumeVideoEvents(kafkaBrokers []string, eventHandler *handlers.VideoEventHandler) {
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: kafkaBrokers,
-		Topic:   "video-events",
-		GroupID: "ranking-service-consumer",
-		// Configure for better performance and reliability
-		MinBytes:    10e3, // 10KB
-		MaxBytes:    10e6, // 10MB
-		MaxWait:     time.Millisecond * 10,
-		ReadBackoffMin: time.Millisecond * 1,
-		ReadBackoffMax: time.Millisecond * 100,
-	})
-	defer reader.Close()
-
-	ctx := context.Background()
+// Config tunes the consumer's worker pool and event batching.
+type Config struct {
+	Workers int
+	// BatchWindow is how long a worker collects events before applying them.
+	BatchWindow time.Duration
+	// BatchSize is the number of events after which a worker applies its batch early.
+	BatchSize int
+}
+
+// workerQueueSize bounds each worker's backlog. When a worker's queue is full the
+// consumer stops fetching, which applies backpressure to the event bus.
+const workerQueueSize = 100
+
+// VideoEventConsumer reads video events from the event bus and processes them on a pool of
+// workers. Messages are routed to workers by a hash of their key (the video ID), so
+// events for the same video are processed in order by a single worker. Each worker
+// aggregates its events per video over a short window and applies each video's
+// events as one combined update.
+type VideoEventConsumer struct {
+	bus          eventbus.Bus
+	eventHandler *handlers.VideoEventHandler
+	config       Config
+	workers      []*worker
+
+	// stopping is closed by Stop to end fetching. processCtx outlives it so queued
+	// events can still be applied, and is only cancelled if draining overruns.
+	stopping   chan struct{}
+	stopOnce   sync.Once
+	done       chan struct{}
+	processCtx context.Context
+	abort      context.CancelFunc
+}
+
+func NewVideoEventConsumer(bus eventbus.Bus, eventHandler *handlers.VideoEventHandler, config Config) *VideoEventConsumer {
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.BatchWindow <= 0 {
+		config.BatchWindow = DefaultBatchWindow
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	workers := make([]*worker, config.Workers)
+	for i := range workers {
+		workers[i] = newWorker(i)
+	}
+	processCtx, abort := context.WithCancel(context.Background())
+	return &VideoEventConsumer{
+		bus:          bus,
+		eventHandler: eventHandler,
+		config:       config,
+		workers:      workers,
+		stopping:     make(chan struct{}),
+		done:         make(chan struct{}),
+		processCtx:   processCtx,
+		abort:        abort,
+	}
+}
+
+// Run consumes events until ctx is cancelled or Stop is called, then applies the
+// events already fetched and commits their offsets before returning.
+func (vc *VideoEventConsumer) Run(ctx context.Context) {
+	defer close(vc.done)
+
+	fetchCtx, stopFetching := context.WithCancel(ctx)
+	defer stopFetching()
+	go func() {
+		select {
+		case <-vc.stopping:
+			stopFetching()
+		case <-fetchCtx.Done():
+		}
+	}()
+
+	subscriber := vc.bus.Subscriber(VideoEventsTopic, "ranking-service-consumer")
+	defer subscriber.Close()
+
+	deadLetters := newDeadLetterWriter(vc.bus)
+	defer deadLetters.Close()
+
+	committer := newOffsetCommitter(subscriber)
+	commitCtx, stopCommitter := context.WithCancel(context.Background())
+	committerDone := make(chan struct{})
+	go func() {
+		committer.run(commitCtx)
+		close(committerDone)
+	}()
+
+	var wg sync.WaitGroup
+	for _, w := range vc.workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			w.run(vc.processCtx, vc.config, vc.eventHandler, deadLetters, committer)
+		}(w)
+	}
 
+	fetchBackoff := retryBackoffMin
 	for {
-		msg, err := reader.ReadMessage(ctx)
+		msg, err := subscriber.Fetch(fetchCtx)
 		if err != nil {
-			log.Printf("Error reading message from Kafka: %v", err)
+			if fetchCtx.Err() != nil {
+				break
+			}
+			log.Printf("Error reading message from event bus, retrying in %v: %v", fetchBackoff, err)
+			select {
+			case <-fetchCtx.Done():
+			case <-time.After(fetchBackoff):
+			}
+			fetchBackoff *= 2
+			if fetchBackoff > retryBackoffMax {
+				fetchBackoff = retryBackoffMax
+			}
 			continue
 		}
+		fetchBackoff = retryBackoffMin
 
-		log.Printf("Received message at Topic:%v Partition:%v Offset:%v Key:%s Value:%s\n", msg.Topic, msg.Partition, msg.Offset, string(msg.Key), string(msg.Value))
+		log.Printf("Received message at Topic:%v Partition:%v Offset:%v Key:%s Size:%d\n", msg.Topic, msg.Partition, msg.Offset, string(msg.Key), len(msg.Value))
 
-		var event models.VideoEvent
-		if err := json.Unmarshal(msg.Value, &event); err != nil {
-			log.Printf("Error unmarshaling Kafka message: %v", err)
-			continue
+		committer.track(msg)
+		// Blocks while the worker's queue is full.
+		vc.workerFor(msg).queue <- msg
+	}
+
+	for _, w := range vc.workers {
+		close(w.queue)
+	}
+	wg.Wait()
+	stopCommitter()
+	<-committerDone
+}
+
+// Stop stops fetching and waits for Run to drain in-flight batches and commit their
+// offsets. If ctx is done first, processing is aborted; events that were not applied
+// keep their offsets uncommitted and are redelivered after a restart.
+func (vc *VideoEventConsumer) Stop(ctx context.Context) error {
+	vc.stopOnce.Do(func() { close(vc.stopping) })
+	select {
+	case <-vc.done:
+		return nil
+	case <-ctx.Done():
+		vc.abort()
+		return fmt.Errorf("consumer did not drain in time: %w", ctx.Err())
+	}
+}
+
+// WorkerStats returns a snapshot of every worker's metrics.
+func (vc *VideoEventConsumer) WorkerStats() []WorkerStats {
+	stats := make([]WorkerStats, len(vc.workers))
+	for i, w := range vc.workers {
+		stats[i] = w.stats()
+	}
+	return stats
+}
+
+func (vc *VideoEventConsumer) workerFor(msg eventbus.Message) *worker {
+	hash := fnv.New32a()
+	hash.Write(msg.Key)
+	return vc.workers[hash.Sum32()%uint32(len(vc.workers))]
+}
+
+// processWithRetry applies a batch of events for one video, retrying transient
+// failures with exponential backoff until they succeed or ctx is done, and giving up
+// immediately on permanent ones. It returns the number of attempts made.
+func processWithRetry(ctx context.Context, eventHandler *handlers.VideoEventHandler, events []*models.VideoEvent) (int, error) {
+	backoff := retryBackoffMin
+	for attempt := 1; ; attempt++ {
+		err := eventHandler.ProcessVideoEvents(ctx, events)
+		if err == nil || isPermanent(err) {
+			return attempt, err
 		}
 
-		if err := eventHandler.ProcessVideoEvent(ctx, &event); err != nil {
-			log.Printf("Error processing video event: %v", err)
+		log.Printf("Retrying %d event(s) for video %s in %v after attempt %d failed: %v", len(events), events[0].VideoID, backoff, attempt, err)
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > retryBackoffMax {
+			backoff = retryBackoffMax
 		}
 	}
-}
\ No newline at end of file
+}
+
+// isPermanent reports whether retrying err cannot succeed.
+func isPermanent(err error) bool {
+	return errors.Is(err, handlers.ErrInvalidEvent) || errors.Is(err, services.ErrVideoNotFound)
+}
@@ -0,0 +1,77 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"realtime-ranking/events"
+	"realtime-ranking/handlers"
+	"realtime-ranking/models"
+	"realtime-ranking/scoring"
+	"realtime-ranking/services"
+	"realtime-ranking/store"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// flakyRepository fails the first failures counter increments, or every one if
+// failures is negative.
+type flakyRepository struct {
+	*store.MemoryStore
+	failures int
+}
+
+func (fr *flakyRepository) IncrementVideoCounters(ctx context.Context, delta models.VideoDelta, score store.ScoreFunc) (*models.Video, error) {
+	if fr.failures != 0 {
+		fr.failures--
+		return nil, errors.New("database unavailable")
+	}
+	return fr.MemoryStore.IncrementVideoCounters(ctx, delta, score)
+}
+
+func TestProcessWithRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		missingVideo  bool
+		timeout       time.Duration
+		wantAttempts  int
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{"succeeds first time", 0, false, time.Minute, 1, false, false},
+		{"transient errors are retried", 3, false, time.Minute, 4, false, false},
+		{"missing video is not retried", 0, true, time.Minute, 1, true, true},
+		{"transient errors are retried until cancelled", -1, false, 250 * time.Millisecond, 2, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := scoring.NewProvider("")
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			ms := store.NewMemoryStore(time.Hour)
+			repository := &flakyRepository{MemoryStore: ms, failures: tt.failures}
+			rankingService := services.NewRankingService(repository, ms, nil, nil, events.FormatJSON, provider, services.LeaderboardConfig{})
+			videoID := uuid.New()
+			if !tt.missingVideo {
+				if err := rankingService.CreateVideo(context.Background(), &models.Video{ID: videoID, Title: "retry"}); err != nil {
+					t.Fatalf("CreateVideo: %v", err)
+				}
+			}
+			eventHandler := handlers.NewVideoEventHandler(rankingService, time.Minute)
+			view := &models.VideoEvent{EventID: "view-1", VideoID: videoID, UserID: "user-1", Action: models.ViewAction}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			attempts, err := processWithRetry(ctx, eventHandler, []*models.VideoEvent{view})
+			if attempts != tt.wantAttempts || (err != nil) != tt.wantErr {
+				t.Fatalf("processWithRetry = %d, %v; want %d attempts and error %v", attempts, err, tt.wantAttempts, tt.wantErr)
+			}
+			if err != nil && isPermanent(err) != tt.wantPermanent {
+				t.Errorf("isPermanent(%v) = %v, want %v", err, !tt.wantPermanent, tt.wantPermanent)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"realtime-ranking/eventbus"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers added to dead-lettered messages, alongside the message's original headers.
+const (
+	deadLetterHeaderPrefix    = "dlq-"
+	deadLetterErrorHeader     = "dlq-error"
+	deadLetterAttemptsHeader  = "dlq-attempts"
+	deadLetterTopicHeader     = "dlq-original-topic"
+	deadLetterPartitionHeader = "dlq-original-partition"
+	deadLetterOffsetHeader    = "dlq-original-offset"
+	deadLetterFailedAtHeader  = "dlq-failed-at"
+)
+
+// redriveIdleTimeout is how long RedriveDeadLetters waits for another message before
+// deciding the dead-letter topic has been drained.
+const redriveIdleTimeout = 5 * time.Second
+
+type deadLetterWriter struct {
+	publisher eventbus.Publisher
+}
+
+func newDeadLetterWriter(bus eventbus.Bus) *deadLetterWriter {
+	return &deadLetterWriter{publisher: bus.Publisher(eventbus.PublisherConfig{Topic: DeadLetterTopic})}
+}
+
+// send copies msg to the dead-letter topic with the failure reason.
+func (dw *deadLetterWriter) send(ctx context.Context, msg eventbus.Message, reason error, attempts int) error {
+	headers := append([]eventbus.Header(nil), msg.Headers...)
+	headers = append(headers,
+		eventbus.Header{Key: deadLetterErrorHeader, Value: []byte(reason.Error())},
+		eventbus.Header{Key: deadLetterAttemptsHeader, Value: []byte(strconv.Itoa(attempts))},
+		eventbus.Header{Key: deadLetterTopicHeader, Value: []byte(msg.Topic)},
+		eventbus.Header{Key: deadLetterPartitionHeader, Value: []byte(strconv.Itoa(msg.Partition))},
+		eventbus.Header{Key: deadLetterOffsetHeader, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		eventbus.Header{Key: deadLetterFailedAtHeader, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	err := dw.publisher.Publish(ctx, eventbus.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("error writing message to %s: %w", DeadLetterTopic, err)
+	}
+	log.Printf("Sent message at Partition:%v Offset:%v to %s: %v", msg.Partition, msg.Offset, DeadLetterTopic, reason)
+	return nil
+}
+
+// sendWithRetry keeps trying to dead-letter msg until it succeeds or ctx is done,
+// reporting whether it was sent. The message's offset must not be committed unless
+// it was, or the event would be lost.
+func (dw *deadLetterWriter) sendWithRetry(ctx context.Context, msg eventbus.Message, reason error, attempts int) bool {
+	for {
+		err := dw.send(ctx, msg, reason, attempts)
+		if err == nil {
+			return true
+		}
+		log.Printf("Error dead-lettering message at Partition:%v Offset:%v, retrying in %v: %v", msg.Partition, msg.Offset, retryBackoffMax, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(retryBackoffMax):
+		}
+	}
+}
+
+func (dw *deadLetterWriter) Close() error {
+	return dw.publisher.Close()
+}
+
+// RedriveDeadLetters moves up to limit messages (all of them if limit <= 0) from the
+// dead-letter topic back to the main topic with their original headers, stopping once
+// the dead-letter topic is idle. Offsets are committed only after a message has been
+// re-published, so an interrupted re-drive can be resumed.
+func RedriveDeadLetters(ctx context.Context, bus eventbus.Bus, limit int) (int, error) {
+	subscriber := bus.Subscriber(DeadLetterTopic, "ranking-service-dlq-redrive")
+	defer subscriber.Close()
+
+	publisher := bus.Publisher(eventbus.PublisherConfig{Topic: VideoEventsTopic})
+	defer publisher.Close()
+
+	redriven := 0
+	for limit <= 0 || redriven < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, redriveIdleTimeout)
+		msg, err := subscriber.Fetch(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				break
+			}
+			return redriven, fmt.Errorf("error reading message from %s: %w", DeadLetterTopic, err)
+		}
+
+		log.Printf("Re-driving message at Partition:%v Offset:%v (original error: %s)", msg.Partition, msg.Offset, headerValue(msg.Headers, deadLetterErrorHeader))
+
+		err = publisher.Publish(ctx, eventbus.Message{
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: originalHeaders(msg.Headers),
+		})
+		if err != nil {
+			return redriven, fmt.Errorf("error writing message to %s: %w", VideoEventsTopic, err)
+		}
+		if err := subscriber.Commit(ctx, msg); err != nil {
+			return redriven, fmt.Errorf("error committing offset on %s: %w", DeadLetterTopic, err)
+		}
+		redriven++
+	}
+	return redriven, nil
+}
+
+// originalHeaders strips the headers added when the message was dead-lettered.
+func originalHeaders(headers []eventbus.Header) []eventbus.Header {
+	original := make([]eventbus.Header, 0, len(headers))
+	for _, header := range headers {
+		if !strings.HasPrefix(header.Key, deadLetterHeaderPrefix) {
+			original = append(original, header)
+		}
+	}
+	return original
+}
+
+func headerValue(headers []eventbus.Header, key string) string {
+	for _, header := range headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
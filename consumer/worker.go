@@ -0,0 +1,208 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"realtime-ranking/eventbus"
+	"realtime-ranking/events"
+	"realtime-ranking/handlers"
+	"realtime-ranking/models"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkerStats describes one consumer worker.
+type WorkerStats struct {
+	Worker        int `json:"worker"`
+	QueueDepth    int `json:"queueDepth"`
+	QueueCapacity int `json:"queueCapacity"`
+	// Processed counts messages handled, including dead-lettered ones.
+	Processed    uint64 `json:"processed"`
+	Batches      uint64 `json:"batches"`
+	Retries      uint64 `json:"retries"`
+	DeadLettered uint64 `json:"deadLettered"`
+	// AvgProcessingMs is the mean time spent per message, including retries.
+	AvgProcessingMs float64   `json:"avgProcessingMs"`
+	LastProcessedAt time.Time `json:"lastProcessedAt,omitempty"`
+}
+
+type worker struct {
+	id    int
+	queue chan eventbus.Message
+
+	processed       atomic.Uint64
+	batches         atomic.Uint64
+	retries         atomic.Uint64
+	deadLettered    atomic.Uint64
+	busyNanos       atomic.Int64
+	lastProcessedAt atomic.Int64
+}
+
+// eventBatch holds the events a worker has collected, grouped by video in arrival order.
+type eventBatch struct {
+	size   int
+	order  []uuid.UUID
+	videos map[uuid.UUID]*videoBatch
+}
+
+type videoBatch struct {
+	messages []eventbus.Message
+	events   []*models.VideoEvent
+}
+
+func newEventBatch() *eventBatch {
+	return &eventBatch{videos: make(map[uuid.UUID]*videoBatch)}
+}
+
+func (b *eventBatch) add(msg eventbus.Message, event *models.VideoEvent) {
+	video, exists := b.videos[event.VideoID]
+	if !exists {
+		video = &videoBatch{}
+		b.videos[event.VideoID] = video
+		b.order = append(b.order, event.VideoID)
+	}
+	video.messages = append(video.messages, msg)
+	video.events = append(video.events, event)
+	b.size++
+}
+
+func newWorker(id int) *worker {
+	return &worker{id: id, queue: make(chan eventbus.Message, workerQueueSize)}
+}
+
+// run collects messages from the worker's queue into batches, applying a batch once
+// config.BatchWindow has passed since its first message or it reaches
+// config.BatchSize events. It returns after the queue is closed and drained.
+func (w *worker) run(ctx context.Context, config Config, eventHandler *handlers.VideoEventHandler, deadLetters *deadLetterWriter, committer *offsetCommitter) {
+	batch := newEventBatch()
+	var flushTimer *time.Timer
+	var flushC <-chan time.Time
+
+	flush := func() {
+		if flushTimer != nil {
+			flushTimer.Stop()
+			flushTimer, flushC = nil, nil
+		}
+		if batch.size == 0 {
+			return
+		}
+		w.flush(ctx, eventHandler, deadLetters, committer, batch)
+		batch = newEventBatch()
+	}
+
+	for {
+		select {
+		case msg, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+
+			event, err := events.Decode(msg.Value, msg.Headers)
+			if err != nil {
+				log.Printf("Error decoding message: %v", err)
+				w.deadLetter(ctx, deadLetters, committer, msg, fmt.Errorf("error decoding video event: %w", err), 0)
+				continue
+			}
+			if err := handlers.ValidateVideoEvent(event); err != nil {
+				w.deadLetter(ctx, deadLetters, committer, msg, err, 0)
+				continue
+			}
+
+			batch.add(msg, event)
+			if batch.size >= config.BatchSize {
+				flush()
+			} else if flushTimer == nil {
+				flushTimer = time.NewTimer(config.BatchWindow)
+				flushC = flushTimer.C
+			}
+		case <-flushC:
+			flushTimer, flushC = nil, nil
+			flush()
+		}
+	}
+}
+
+// flush applies each video's events as one update, dead-lettering a video's messages
+// if its update can never be applied, and marks the messages that were dealt with
+// done. Transient failures are retried until ctx is cancelled; the messages of the
+// video being retried then, and of the videos after it, are left uncommitted.
+func (w *worker) flush(ctx context.Context, eventHandler *handlers.VideoEventHandler, deadLetters *deadLetterWriter, committer *offsetCommitter, batch *eventBatch) {
+	started := time.Now()
+	var done []eventbus.Message
+	for _, videoID := range batch.order {
+		video := batch.videos[videoID]
+		if ctx.Err() != nil {
+			break
+		}
+
+		attempts, err := processWithRetry(ctx, eventHandler, video.events)
+		if attempts > 1 {
+			w.retries.Add(uint64(attempts - 1))
+		}
+		if err == nil {
+			done = append(done, video.messages...)
+			continue
+		}
+		if !isPermanent(err) {
+			// Aborted, not failed: leave the events for redelivery.
+			break
+		}
+
+		log.Printf("Error processing %d event(s) for video %s after %d attempt(s): %v", len(video.events), videoID, attempts, err)
+		for _, msg := range video.messages {
+			if deadLetters.sendWithRetry(ctx, msg, err, attempts) {
+				w.deadLettered.Add(1)
+				done = append(done, msg)
+			}
+		}
+	}
+	w.record(started, len(done))
+	w.batches.Add(1)
+
+	// Only now, with the events applied or dead-lettered, may their offsets be committed.
+	for _, msg := range done {
+		committer.markDone(msg)
+	}
+}
+
+// deadLetter sends a message that can never be processed straight to the dead-letter
+// topic without waiting for the rest of the batch.
+func (w *worker) deadLetter(ctx context.Context, deadLetters *deadLetterWriter, committer *offsetCommitter, msg eventbus.Message, reason error, attempts int) {
+	started := time.Now()
+	if !deadLetters.sendWithRetry(ctx, msg, reason, attempts) {
+		return
+	}
+	w.deadLettered.Add(1)
+	w.record(started, 1)
+	committer.markDone(msg)
+}
+
+func (w *worker) record(started time.Time, messages int) {
+	finished := time.Now()
+	w.processed.Add(uint64(messages))
+	w.busyNanos.Add(int64(finished.Sub(started)))
+	w.lastProcessedAt.Store(finished.UnixNano())
+}
+
+func (w *worker) stats() WorkerStats {
+	stats := WorkerStats{
+		Worker:        w.id,
+		QueueDepth:    len(w.queue),
+		QueueCapacity: cap(w.queue),
+		Processed:     w.processed.Load(),
+		Batches:       w.batches.Load(),
+		Retries:       w.retries.Load(),
+		DeadLettered:  w.deadLettered.Load(),
+	}
+	if stats.Processed > 0 {
+		stats.AvgProcessingMs = float64(w.busyNanos.Load()) / float64(stats.Processed) / float64(time.Millisecond)
+	}
+	if lastProcessedAt := w.lastProcessedAt.Load(); lastProcessedAt > 0 {
+		stats.LastProcessedAt = time.Unix(0, lastProcessedAt).UTC()
+	}
+	return stats
+}
@@ -15,6 +15,475 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/consumer/workers": {
+            "get": {
+                "description": "Returns the queue depth, throughput, retries and dead-lettered count of each Kafka consumer worker",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get event consumer worker metrics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/consumer.WorkerStats"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scoring": {
+            "get": {
+                "description": "Returns the scoring weights currently applied to events and personalized rankings, along with their version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the active scoring config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/scoring.Config"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scoring/reload": {
+            "post": {
+                "description": "Re-reads the scoring config file and activates it without a restart. The previous config stays active if the new one is invalid or changes the config without changing its version. When the version changes, every video's all-time score is recomputed with the new config in the background.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reload the scoring config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/scoring.Config"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/categories/videos/top": {
+            "get": {
+                "description": "Retrieve the videos in any of the given categories ranked by their all-time score. A video in several of the categories is listed once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Get top-ranked videos across categories",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Category names (repeat for each category)",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start index",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of videos to retrieve",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Video"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{name}/videos/top": {
+            "get": {
+                "description": "Retrieve the videos in a category ranked by their all-time score. Category names are matched case-insensitively.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Get top-ranked videos in a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start index",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of videos to retrieve",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Video"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/creators/top": {
+            "get": {
+                "description": "Retrieve creators ranked by the aggregate score of their videos: the sum of all their video scores, or the mean of their best CREATOR_TOP_K videos when configured",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "creators"
+                ],
+                "summary": "Get top-ranked creators",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start index",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of creators to retrieve",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CreatorScore"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/creators/{id}/videos/top": {
+            "get": {
+                "description": "Retrieve a creator's videos ranked by their all-time score",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "creators"
+                ],
+                "summary": "Get a creator's top-ranked videos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start index",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of videos to retrieve",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Video"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/videos/top": {
+            "get": {
+                "description": "Retrieve the videos with any of the given tags ranked by their all-time score. Only available when TAG_LEADERBOARDS is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get top-ranked videos across tags",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tag names (repeat for each tag)",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start index",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of videos to retrieve",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Video"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{name}/videos/top": {
+            "get": {
+                "description": "Retrieve the videos with a tag ranked by their all-time score. Only available when TAG_LEADERBOARDS is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get top-ranked videos with a tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start index",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of videos to retrieve",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Video"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/users/{userID}/preferences": {
             "post": {
                 "description": "Updates a user's video category preferences",
@@ -90,13 +559,18 @@ const docTemplate = `{
                         "type": "integer",
                         "description": "Start index",
                         "name": "start",
-                        "in": "query"
+                        "in": "query",
+                        "minimum": 0,
+                        "default": 0
                     },
                     {
                         "type": "integer",
                         "description": "Number of videos to retrieve",
                         "name": "count",
-                        "in": "query"
+                        "in": "query",
+                        "maximum": 100,
+                        "minimum": 1,
+                        "default": 10
                     }
                 ],
                 "responses": {
@@ -114,13 +588,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/videos.ErrorResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
                     }
                 }
             }
         },
         "/videos": {
             "post": {
-                "description": "Creates a new video with the given title and Base64 encoded data",
+                "description": "Creates a new video with the given title, Base64 encoded data and optional creator, language, duration, categories and tags. Categories and tags are trimmed, lower-cased and de-duplicated.",
                 "consumes": [
                     "application/json"
                 ],
@@ -179,12 +659,51 @@ const docTemplate = `{
                         "type": "integer",
                         "description": "Start index",
                         "name": "start",
-                        "in": "query"
+                        "in": "query",
+                        "minimum": 0,
+                        "default": 0
                     },
                     {
                         "type": "integer",
                         "description": "Number of videos to retrieve",
                         "name": "count",
+                        "in": "query",
+                        "maximum": 100,
+                        "minimum": 1,
+                        "default": 10
+                    },
+                    {
+                        "enum": [
+                            "all_time",
+                            "hot"
+                        ],
+                        "type": "string",
+                        "default": "all_time",
+                        "description": "Ranking mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "1h",
+                            "24h",
+                            "7d"
+                        ],
+                        "type": "string",
+                        "description": "Rolling time window; when set, videos are ranked by score gained within the window and mode must be omitted",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 region; when set, videos are ranked by score gained from events in the region, falling back to the global leaderboard if too few videos are ranked there",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 639 language; when set, videos are ranked by score gained from events with the language's locale, falling back to the global leaderboard if too few videos are ranked there",
+                        "name": "language",
                         "in": "query"
                     }
                 ],
@@ -196,6 +715,18 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/models.Video"
                             }
+                        },
+                        "headers": {
+                            "X-Leaderboard": {
+                                "type": "string",
+                                "description": "For region and language queries, the leaderboard served: region:<code>, language:<code> or global"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
                         }
                     },
                     "500": {
@@ -209,7 +740,64 @@ const docTemplate = `{
         },
         "/videos/{id}": {
             "put": {
-                "description": "Updates a video's title and Base64 encoded data",
+                "description": "Partially updates a video's title, Base64 encoded data, language, duration, categories and tags. Omitted fields are left unchanged, categories and tags replace the current lists when present, and engagement counters and ranking are never modified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Update video metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Metadata fields to update",
+                        "name": "video",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateVideoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Video"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially updates a video's title, Base64 encoded data, language, duration, categories and tags. Omitted fields are left unchanged, categories and tags replace the current lists when present, and engagement counters and ranking are never modified.",
                 "consumes": [
                     "application/json"
                 ],
@@ -219,7 +807,7 @@ const docTemplate = `{
                 "tags": [
                     "videos"
                 ],
-                "summary": "Update video",
+                "summary": "Update video metadata",
                 "parameters": [
                     {
                         "type": "string",
@@ -229,7 +817,7 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Video object to be updated",
+                        "description": "Metadata fields to update",
                         "name": "video",
                         "in": "body",
                         "required": true,
@@ -251,6 +839,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/videos.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -272,7 +866,154 @@ const docTemplate = `{
                 "tags": [
                     "videos"
                 ],
-                "summary": "Handle video comment event",
+                "summary": "Handle video comment event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 region the event came from",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-assigned event ID; repeated submissions with the same key are counted once",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/videos.EventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/videos/{id}/like": {
+            "post": {
+                "description": "Records a video like and updates the score",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Handle video like event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 region the event came from",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-assigned event ID; repeated submissions with the same key are counted once",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/videos.EventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/videos/{id}/neighbors": {
+            "get": {
+                "description": "Returns the video's rank along with up to radius videos ranked directly above and below it in the all-time leaderboard",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "videos"
+                ],
+                "summary": "Get the videos ranked around a video",
                 "parameters": [
                     {
                         "type": "string",
@@ -282,18 +1023,20 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
-                        "in": "query",
-                        "required": true
+                        "maximum": 50,
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of videos on each side",
+                        "name": "radius",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/videos.SuccessResponse"
+                            "$ref": "#/definitions/models.VideoNeighbors"
                         }
                     },
                     "400": {
@@ -302,6 +1045,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/videos.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -311,19 +1060,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/videos/{id}/like": {
-            "post": {
-                "description": "Records a video like and updates the score",
-                "consumes": [
-                    "application/json"
-                ],
+        "/videos/{id}/rank": {
+            "get": {
+                "description": "Returns the video's 0-based position in the all-time leaderboard, its score, the number of ranked videos and the percentage of them ranked at or below it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "videos"
                 ],
-                "summary": "Handle video like event",
+                "summary": "Get a video's rank",
                 "parameters": [
                     {
                         "type": "string",
@@ -331,20 +1077,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/videos.SuccessResponse"
+                            "$ref": "#/definitions/models.VideoRank"
                         }
                     },
                     "400": {
@@ -353,6 +1092,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/videos.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -389,13 +1134,31 @@ const docTemplate = `{
                         "name": "userID",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 region the event came from",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-assigned event ID; repeated submissions with the same key are counted once",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/videos.SuccessResponse"
+                            "$ref": "#/definitions/videos.EventResponse"
                         }
                     },
                     "400": {
@@ -404,6 +1167,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/videos.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -440,13 +1209,31 @@ const docTemplate = `{
                         "name": "userID",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 region the event came from",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-assigned event ID; repeated submissions with the same key are counted once",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/videos.SuccessResponse"
+                            "$ref": "#/definitions/videos.EventResponse"
                         }
                     },
                     "400": {
@@ -455,6 +1242,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/videos.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -498,13 +1291,31 @@ const docTemplate = `{
                         "name": "duration",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 region the event came from",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-assigned event ID; repeated submissions with the same key are counted once",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/videos.SuccessResponse"
+                            "$ref": "#/definitions/videos.EventResponse"
                         }
                     },
                     "400": {
@@ -513,6 +1324,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/videos.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/videos.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -524,6 +1341,40 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "consumer.WorkerStats": {
+            "type": "object",
+            "properties": {
+                "avgProcessingMs": {
+                    "description": "AvgProcessingMs is the mean time spent per message, including retries.",
+                    "type": "number"
+                },
+                "batches": {
+                    "type": "integer"
+                },
+                "deadLettered": {
+                    "type": "integer"
+                },
+                "lastProcessedAt": {
+                    "type": "string"
+                },
+                "processed": {
+                    "description": "Processed counts messages handled, including dead-lettered ones.",
+                    "type": "integer"
+                },
+                "queueCapacity": {
+                    "type": "integer"
+                },
+                "queueDepth": {
+                    "type": "integer"
+                },
+                "retries": {
+                    "type": "integer"
+                },
+                "worker": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.CreateVideoRequest": {
             "type": "object",
             "required": [
@@ -531,9 +1382,35 @@ const docTemplate = `{
                 "title"
             ],
             "properties": {
+                "categories": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "creatorId": {
+                    "type": "string",
+                    "maxLength": 255
+                },
                 "data": {
                     "type": "string"
                 },
+                "durationSeconds": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "language": {
+                    "type": "string",
+                    "maxLength": 35
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string",
                     "maxLength": 255,
@@ -541,6 +1418,17 @@ const docTemplate = `{
                 }
             }
         },
+        "models.CreatorScore": {
+            "type": "object",
+            "properties": {
+                "creatorId": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
         "models.UpdateUserPreferencesRequest": {
             "type": "object",
             "required": [
@@ -557,13 +1445,32 @@ const docTemplate = `{
         },
         "models.UpdateVideoRequest": {
             "type": "object",
-            "required": [
-                "data",
-                "title"
-            ],
             "properties": {
+                "categories": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "data": {
-                    "type": "string"
+                    "type": "string",
+                    "minLength": 1
+                },
+                "durationSeconds": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "language": {
+                    "type": "string",
+                    "maxLength": 35
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "title": {
                     "type": "string",
@@ -575,27 +1482,55 @@ const docTemplate = `{
         "models.Video": {
             "type": "object",
             "properties": {
+                "categories": {
+                    "description": "Categories and Tags are normalized with NormalizeLabels.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "comments": {
                     "type": "integer"
                 },
                 "createdAt": {
                     "type": "string"
                 },
+                "creatorId": {
+                    "description": "CreatorID identifies the user who published the video.",
+                    "type": "string"
+                },
                 "data": {
                     "type": "string"
                 },
+                "durationSeconds": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "string"
                 },
+                "language": {
+                    "description": "Language is a BCP 47 language tag such as \"en\" or \"vi\".",
+                    "type": "string"
+                },
                 "likes": {
                     "type": "integer"
                 },
                 "score": {
                     "type": "number"
                 },
+                "scoringVersion": {
+                    "description": "ScoringVersion is the scoring config version that computed Score.",
+                    "type": "string"
+                },
                 "shares": {
                     "type": "integer"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 },
@@ -610,6 +1545,117 @@ const docTemplate = `{
                 }
             }
         },
+        "models.VideoNeighbors": {
+            "type": "object",
+            "properties": {
+                "above": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Video"
+                    }
+                },
+                "below": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Video"
+                    }
+                },
+                "rank": {
+                    "$ref": "#/definitions/models.VideoRank"
+                }
+            }
+        },
+        "models.VideoRank": {
+            "type": "object",
+            "properties": {
+                "percentile": {
+                    "description": "Percentile is the percentage of ranked videos at or below this one, so the top\nvideo is at 100.",
+                    "type": "number"
+                },
+                "rank": {
+                    "description": "Rank is 0-based; the top video has rank 0.",
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "total": {
+                    "description": "Total is the number of ranked videos.",
+                    "type": "integer"
+                },
+                "videoId": {
+                    "type": "string"
+                }
+            }
+        },
+        "scoring.Config": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "$ref": "#/definitions/scoring.EventWeights"
+                },
+                "personalization": {
+                    "$ref": "#/definitions/scoring.PersonalizationWeights"
+                },
+                "scorers": {
+                    "description": "Scorers selects the ranking formula for each leaderboard by scorer name.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "scoring.EventWeights": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "number"
+                },
+                "like": {
+                    "type": "number"
+                },
+                "share": {
+                    "type": "number"
+                },
+                "view": {
+                    "type": "number"
+                },
+                "watchSecond": {
+                    "type": "number"
+                }
+            }
+        },
+        "scoring.PersonalizationWeights": {
+            "type": "object",
+            "properties": {
+                "categoryMatch": {
+                    "type": "number"
+                },
+                "comment": {
+                    "type": "number"
+                },
+                "like": {
+                    "type": "number"
+                },
+                "recencyHours": {
+                    "description": "RecencyHours controls how quickly interaction boosts fade: a boost is divided by\n1 + hoursSinceLastView/RecencyHours.",
+                    "type": "number"
+                },
+                "share": {
+                    "type": "number"
+                },
+                "view": {
+                    "type": "number"
+                },
+                "watchSecond": {
+                    "type": "number"
+                }
+            }
+        },
         "videos.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -621,6 +1667,20 @@ const docTemplate = `{
                 }
             }
         },
+        "videos.EventResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
         "videos.SuccessResponse": {
             "type": "object",
             "properties": {
@@ -629,6 +1689,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin endpoints require \"Bearer <ADMIN_TOKEN>\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,71 @@
+// Package eventbus decouples event producers and consumers from the transport that
+// carries their messages, so the service can run against Kafka or entirely in process.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message is a message on the bus.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers []Header
+	// Partition and Offset locate the message within its topic. They are set on
+	// fetched messages and ignored when publishing.
+	Partition int
+	Offset    int64
+}
+
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Publisher writes messages to the bus. Messages without a Topic go to the
+// publisher's default topic.
+type Publisher interface {
+	Publish(ctx context.Context, messages ...Message) error
+	Close() error
+}
+
+// Subscriber reads messages from one topic as a member of a consumer group.
+// Messages sharing a key are delivered in the order they were published.
+type Subscriber interface {
+	// Fetch blocks until a message is available or ctx is done.
+	Fetch(ctx context.Context) (Message, error)
+	// Commit records that messages have been processed, so the group does not
+	// receive them again.
+	Commit(ctx context.Context, messages ...Message) error
+	Close() error
+}
+
+// PublisherConfig configures a Publisher.
+type PublisherConfig struct {
+	// Topic is used for messages that do not set one.
+	Topic string
+	// Async lets Publish return before the bus has accepted the messages, trading
+	// delivery guarantees for latency.
+	Async bool
+}
+
+// Bus creates publishers and subscribers on a transport.
+type Bus interface {
+	Publisher(config PublisherConfig) Publisher
+	Subscriber(topic, group string) Subscriber
+	Close() error
+}
+
+// New returns the bus named by kind: "kafka" (using kafkaBrokers) or "memory".
+func New(kind string, kafkaBrokers []string) (Bus, error) {
+	switch kind {
+	case "kafka":
+		return NewKafkaBus(kafkaBrokers), nil
+	case "memory":
+		return NewMemoryBus(), nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %q: expected kafka or memory", kind)
+	}
+}
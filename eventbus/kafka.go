@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaBus carries messages on Kafka.
+type KafkaBus struct {
+	brokers []string
+}
+
+func NewKafkaBus(brokers []string) *KafkaBus {
+	return &KafkaBus{brokers: brokers}
+}
+
+func (kb *KafkaBus) Publisher(config PublisherConfig) Publisher {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(kb.brokers...),
+		Balancer:     &kafka.Hash{},
+		WriteTimeout: 10 * time.Second,
+		RequiredAcks: kafka.RequireAll,
+		MaxAttempts:  5,
+	}
+	if config.Async {
+		// Configure for better performance and reliability
+		writer.ReadTimeout = 10 * time.Second
+		writer.RequiredAcks = kafka.RequireOne
+		writer.MaxAttempts = 3
+		writer.Async = true
+		writer.BatchSize = 100
+		writer.BatchTimeout = 10 * time.Millisecond
+	}
+	return &kafkaPublisher{writer: writer, topic: config.Topic}
+}
+
+func (kb *KafkaBus) Subscriber(topic, group string) Subscriber {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: kb.brokers,
+		Topic:   topic,
+		GroupID: group,
+		// Offsets are only committed by Commit.
+		CommitInterval: 0,
+		// Configure for better performance and reliability
+		MinBytes:       10e3, // 10KB
+		MaxBytes:       10e6, // 10MB
+		MaxWait:        10 * time.Millisecond,
+		ReadBackoffMin: time.Millisecond,
+		ReadBackoffMax: 100 * time.Millisecond,
+	})
+	return &kafkaSubscriber{reader: reader}
+}
+
+func (kb *KafkaBus) Close() error {
+	return nil
+}
+
+type kafkaPublisher struct {
+	writer *kafka.Writer
+	topic  string
+}
+
+func (kp *kafkaPublisher) Publish(ctx context.Context, messages ...Message) error {
+	kafkaMessages := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		topic := message.Topic
+		if topic == "" {
+			topic = kp.topic
+		}
+		kafkaMessages[i] = kafka.Message{
+			Topic:   topic,
+			Key:     message.Key,
+			Value:   message.Value,
+			Headers: toKafkaHeaders(message.Headers),
+		}
+	}
+	return kp.writer.WriteMessages(ctx, kafkaMessages...)
+}
+
+func (kp *kafkaPublisher) Close() error {
+	return kp.writer.Close()
+}
+
+type kafkaSubscriber struct {
+	reader *kafka.Reader
+}
+
+func (ks *kafkaSubscriber) Fetch(ctx context.Context) (Message, error) {
+	msg, err := ks.reader.FetchMessage(ctx)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Topic:     msg.Topic,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   fromKafkaHeaders(msg.Headers),
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	}, nil
+}
+
+func (ks *kafkaSubscriber) Commit(ctx context.Context, messages ...Message) error {
+	kafkaMessages := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		kafkaMessages[i] = kafka.Message{Topic: message.Topic, Partition: message.Partition, Offset: message.Offset}
+	}
+	return ks.reader.CommitMessages(ctx, kafkaMessages...)
+}
+
+func (ks *kafkaSubscriber) Close() error {
+	return ks.reader.Close()
+}
+
+func toKafkaHeaders(headers []Header) []kafka.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	kafkaHeaders := make([]kafka.Header, len(headers))
+	for i, header := range headers {
+		kafkaHeaders[i] = kafka.Header{Key: header.Key, Value: header.Value}
+	}
+	return kafkaHeaders
+}
+
+func fromKafkaHeaders(kafkaHeaders []kafka.Header) []Header {
+	if len(kafkaHeaders) == 0 {
+		return nil
+	}
+	headers := make([]Header, len(kafkaHeaders))
+	for i, header := range kafkaHeaders {
+		headers[i] = Header{Key: header.Key, Value: header.Value}
+	}
+	return headers
+}
@@ -0,0 +1,144 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// memoryTopicCapacity bounds each in-memory topic. Publish blocks while a topic with
+// subscribers is full, the way a slow consumer would eventually stall a Kafka
+// producer. A full topic that nobody subscribes to, such as the dead-letter topic
+// outside redrive-dlq, drops its oldest message instead, since blocking would stall
+// the publisher forever.
+const memoryTopicCapacity = 10000
+
+// MemoryBus carries messages over Go channels within the process, for local runs and
+// tests. Messages are lost when the process exits. Each message is delivered to one
+// subscriber of its topic regardless of group, and commits are no-ops.
+type MemoryBus struct {
+	mu     sync.Mutex
+	topics map[string]*memoryTopic
+}
+
+type memoryTopic struct {
+	messages    chan Message
+	subscribers atomic.Int32
+
+	mu         sync.Mutex
+	nextOffset int64
+}
+
+// enqueue adds message to the topic, dropping the oldest message if the topic is full
+// and has no subscribers. The caller must hold t.mu.
+func (t *memoryTopic) enqueue(ctx context.Context, message Message) error {
+	for t.subscribers.Load() == 0 {
+		select {
+		case t.messages <- message:
+			return nil
+		default:
+		}
+		select {
+		case dropped := <-t.messages:
+			log.Printf("Dropping message at Topic:%v Offset:%v: topic is full and has no subscribers", dropped.Topic, dropped.Offset)
+		default:
+		}
+	}
+	select {
+	case t.messages <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{topics: make(map[string]*memoryTopic)}
+}
+
+func (mb *MemoryBus) topic(name string) *memoryTopic {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	topic, exists := mb.topics[name]
+	if !exists {
+		topic = &memoryTopic{messages: make(chan Message, memoryTopicCapacity)}
+		mb.topics[name] = topic
+	}
+	return topic
+}
+
+func (mb *MemoryBus) Publisher(config PublisherConfig) Publisher {
+	return &memoryPublisher{bus: mb, topic: config.Topic}
+}
+
+func (mb *MemoryBus) Subscriber(topic, group string) Subscriber {
+	subscriber := &memorySubscriber{topic: mb.topic(topic)}
+	subscriber.topic.subscribers.Add(1)
+	return subscriber
+}
+
+func (mb *MemoryBus) Close() error {
+	return nil
+}
+
+type memoryPublisher struct {
+	bus   *MemoryBus
+	topic string
+}
+
+func (mp *memoryPublisher) Publish(ctx context.Context, messages ...Message) error {
+	for _, message := range messages {
+		if message.Topic == "" {
+			message.Topic = mp.topic
+		}
+		if message.Topic == "" {
+			return fmt.Errorf("message has no topic")
+		}
+		topic := mp.bus.topic(message.Topic)
+
+		// Offsets are assigned and messages enqueued under one lock so that they
+		// stay in offset order.
+		topic.mu.Lock()
+		message.Partition = 0
+		message.Offset = topic.nextOffset
+		err := topic.enqueue(ctx, message)
+		if err == nil {
+			topic.nextOffset++
+		}
+		topic.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mp *memoryPublisher) Close() error {
+	return nil
+}
+
+type memorySubscriber struct {
+	topic     *memoryTopic
+	closeOnce sync.Once
+}
+
+func (ms *memorySubscriber) Fetch(ctx context.Context) (Message, error) {
+	select {
+	case message := <-ms.topic.messages:
+		return message, nil
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	}
+}
+
+func (ms *memorySubscriber) Commit(ctx context.Context, messages ...Message) error {
+	return nil
+}
+
+func (ms *memorySubscriber) Close() error {
+	ms.closeOnce.Do(func() { ms.topic.subscribers.Add(-1) })
+	return nil
+}
@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryBusFullTopic(t *testing.T) {
+	tests := []struct {
+		name       string
+		subscribed bool
+		wantErr    error
+		wantOffset int64
+	}{
+		// Nothing will ever drain the topic, so the oldest message makes way.
+		{"without subscribers", false, nil, 1},
+		// A subscriber will catch up, so the publisher waits for it.
+		{"with a subscriber", true, context.DeadlineExceeded, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewMemoryBus()
+			publisher := bus.Publisher(PublisherConfig{Topic: "events"})
+			if tt.subscribed {
+				subscriber := bus.Subscriber("events", "group")
+				defer subscriber.Close()
+			}
+
+			ctx := context.Background()
+			for i := 0; i < memoryTopicCapacity; i++ {
+				if err := publisher.Publish(ctx, Message{}); err != nil {
+					t.Fatalf("Publish #%d: %v", i, err)
+				}
+			}
+			ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			if err := publisher.Publish(ctx, Message{}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Publish to a full topic = %v, want %v", err, tt.wantErr)
+			}
+
+			subscriber := bus.Subscriber("events", "group")
+			defer subscriber.Close()
+			message, err := subscriber.Fetch(context.Background())
+			if err != nil {
+				t.Fatalf("Fetch: %v", err)
+			}
+			if message.Offset != tt.wantOffset {
+				t.Errorf("first message offset = %d, want %d", message.Offset, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package events encodes and decodes VideoEvents on the wire. Events are JSON by
+// default or Protobuf when configured; the format travels in the content-type header,
+// so consumers decode either regardless of their own setting.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"realtime-ranking/eventbus"
+	"realtime-ranking/models"
+)
+
+// ContentTypeHeader names the header carrying a message's Format. Messages without
+// it are JSON, as published before Protobuf was supported.
+const ContentTypeHeader = "content-type"
+
+// Format is a wire format for VideoEvents, identified by its content type.
+type Format string
+
+const (
+	FormatJSON     Format = "application/json"
+	FormatProtobuf Format = "application/x-protobuf"
+)
+
+// ParseFormat parses the name of a format as used in configuration.
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "", "json":
+		return FormatJSON, nil
+	case "protobuf":
+		return FormatProtobuf, nil
+	default:
+		return "", fmt.Errorf("unknown event encoding %q: expected json or protobuf", name)
+	}
+}
+
+// Encode serializes event in format.
+func Encode(event *models.VideoEvent, format Format) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.Marshal(event)
+	case FormatProtobuf:
+		return marshalProtobuf(event)
+	default:
+		return nil, fmt.Errorf("unsupported event format %q", format)
+	}
+}
+
+// Decode deserializes an event in the format named by its content-type header.
+// Events of every schema version up to models.VideoEventSchemaVersion are accepted
+// and returned with typed payloads.
+func Decode(value []byte, headers []eventbus.Header) (*models.VideoEvent, error) {
+	format := FormatJSON
+	for _, header := range headers {
+		if header.Key == ContentTypeHeader {
+			format = Format(header.Value)
+		}
+	}
+
+	var event *models.VideoEvent
+	var err error
+	switch format {
+	case FormatJSON:
+		event, err = unmarshalJSON(value)
+	case FormatProtobuf:
+		event, err = unmarshalProtobuf(value)
+	default:
+		return nil, fmt.Errorf("unsupported event content type %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if event.SchemaVersion > models.VideoEventSchemaVersion {
+		return nil, fmt.Errorf("unsupported event schema version %d", event.SchemaVersion)
+	}
+	return event, nil
+}
+
+// legacyPayload holds the untyped payload of version 1 events.
+type legacyPayload struct {
+	Value *float64 `json:"value"`
+}
+
+func unmarshalJSON(value []byte) (*models.VideoEvent, error) {
+	var event models.VideoEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return nil, fmt.Errorf("error unmarshaling video event: %w", err)
+	}
+	if event.SchemaVersion > 1 {
+		return &event, nil
+	}
+
+	// Version 1 events have no schema_version and put the watch time in "value".
+	event.SchemaVersion = 1
+	var legacy legacyPayload
+	if err := json.Unmarshal(value, &legacy); err != nil {
+		return nil, fmt.Errorf("error unmarshaling version 1 video event payload: %w", err)
+	}
+	if event.Action == models.WatchTimeAction && legacy.Value != nil {
+		event.WatchTime = &models.WatchTimePayload{Seconds: int(*legacy.Value)}
+	}
+	return &event, nil
+}
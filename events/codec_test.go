@@ -0,0 +1,277 @@
+package events
+
+import (
+	"realtime-ranking/eventbus"
+	"realtime-ranking/events/eventspb"
+	"realtime-ranking/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var testVideoID = uuid.MustParse("6f1c9a3e-8d2b-4c57-9e0a-1b2c3d4e5f60")
+
+func testEvent(action string) *models.VideoEvent {
+	return &models.VideoEvent{
+		SchemaVersion: models.VideoEventSchemaVersion,
+		EventID:       "evt-" + action,
+		Timestamp:     time.Date(2024, 6, 1, 12, 30, 45, 123456789, time.UTC),
+		VideoID:       testVideoID,
+		Action:        action,
+		UserID:        "user-1",
+	}
+}
+
+func protobufHeaders() []eventbus.Header {
+	return []eventbus.Header{{Key: ContentTypeHeader, Value: []byte(FormatProtobuf)}}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	withWatchTime := testEvent(models.WatchTimeAction)
+	withWatchTime.WatchTime = &models.WatchTimePayload{Seconds: 95}
+
+	withAudience := testEvent(models.LikeAction)
+	withAudience.Region = "VN"
+	withAudience.Locale = "vi-VN"
+
+	minimal := &models.VideoEvent{SchemaVersion: 1, VideoID: testVideoID, Action: models.ViewAction}
+
+	tests := []struct {
+		name  string
+		event *models.VideoEvent
+	}{
+		{"view", testEvent(models.ViewAction)},
+		{"like", testEvent(models.LikeAction)},
+		{"comment", testEvent(models.CommentAction)},
+		{"share", testEvent(models.ShareAction)},
+		{"watch_time", withWatchTime},
+		{"region and locale", withAudience},
+		{"no id, timestamp or user", minimal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := Encode(tt.event, FormatProtobuf)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := Decode(encoded, protobufHeaders())
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.event) {
+				t.Errorf("round trip mismatch:\n got  %+v\n want %+v", decoded, tt.event)
+			}
+		})
+	}
+}
+
+func TestDecodeProtobufFromOtherProducers(t *testing.T) {
+	timestamp := time.Date(2024, 6, 1, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		name    string
+		message *eventspb.VideoEvent
+		want    *models.VideoEvent
+		wantErr bool
+	}{
+		{
+			name: "watch time",
+			message: &eventspb.VideoEvent{
+				SchemaVersion: 2,
+				EventId:       "evt-1",
+				Timestamp:     timestamppb.New(timestamp),
+				VideoId:       testVideoID.String(),
+				Action:        eventspb.Action_ACTION_WATCH_TIME,
+				UserId:        "user-1",
+				Payload:       &eventspb.VideoEvent_WatchTime{WatchTime: &eventspb.WatchTimePayload{Seconds: 30}},
+				Region:        "VN",
+			},
+			want: &models.VideoEvent{
+				SchemaVersion: 2,
+				EventID:       "evt-1",
+				Timestamp:     timestamp,
+				VideoID:       testVideoID,
+				Action:        models.WatchTimeAction,
+				UserID:        "user-1",
+				WatchTime:     &models.WatchTimePayload{Seconds: 30},
+				Region:        "VN",
+			},
+		},
+		{
+			name:    "missing action and video",
+			message: &eventspb.VideoEvent{SchemaVersion: 2},
+			want:    &models.VideoEvent{SchemaVersion: 2},
+		},
+		{
+			name:    "unknown action",
+			message: &eventspb.VideoEvent{VideoId: testVideoID.String(), Action: eventspb.Action(42)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid video ID",
+			message: &eventspb.VideoEvent{VideoId: "not-a-uuid", Action: eventspb.Action_ACTION_VIEW},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := proto.Marshal(tt.message)
+			if err != nil {
+				t.Fatalf("proto.Marshal: %v", err)
+			}
+			decoded, err := Decode(encoded, protobufHeaders())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(decoded, tt.want) {
+				t.Errorf("Decode = %+v, want %+v", decoded, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeProtobufRejectsUnknownAction(t *testing.T) {
+	if _, err := Encode(testEvent("dislike"), FormatProtobuf); err == nil {
+		t.Fatal("Encode succeeded for an unknown action")
+	}
+}
+
+func TestDecodeProtobufRejectsMalformedInput(t *testing.T) {
+	encoded, err := Encode(testEvent(models.ViewAction), FormatProtobuf)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if _, err := Decode(encoded[:len(encoded)-3], protobufHeaders()); err == nil {
+		t.Fatal("Decode succeeded for a truncated event")
+	}
+}
+
+func TestDecodeVersion1JSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		wantWatchTime *models.WatchTimePayload
+	}{
+		{
+			name:          "watch_time with integer value",
+			value:         `{"timestamp":"2024-06-01T12:30:45Z","video_id":"6f1c9a3e-8d2b-4c57-9e0a-1b2c3d4e5f60","action":"watch_time","user_id":"user-1","value":30}`,
+			wantWatchTime: &models.WatchTimePayload{Seconds: 30},
+		},
+		{
+			name:  "view ignores value",
+			value: `{"timestamp":"2024-06-01T12:30:45Z","video_id":"6f1c9a3e-8d2b-4c57-9e0a-1b2c3d4e5f60","action":"view","user_id":"user-1","value":1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := Decode([]byte(tt.value), nil)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if event.SchemaVersion != 1 {
+				t.Errorf("SchemaVersion = %d, want 1", event.SchemaVersion)
+			}
+			if event.VideoID != testVideoID || event.UserID != "user-1" {
+				t.Errorf("decoded %+v, want video %s and user user-1", event, testVideoID)
+			}
+			if !reflect.DeepEqual(event.WatchTime, tt.wantWatchTime) {
+				t.Errorf("WatchTime = %+v, want %+v", event.WatchTime, tt.wantWatchTime)
+			}
+		})
+	}
+}
+
+func TestDecodeVersion2JSON(t *testing.T) {
+	value := `{"schema_version":2,"event_id":"evt-1","timestamp":"2024-06-01T12:30:45Z","video_id":"6f1c9a3e-8d2b-4c57-9e0a-1b2c3d4e5f60","action":"watch_time","user_id":"user-1","watch_time":{"seconds":42},"region":"VN","locale":"vi-VN"}`
+	event, err := Decode([]byte(value), []eventbus.Header{{Key: ContentTypeHeader, Value: []byte(FormatJSON)}})
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	want := &models.VideoEvent{
+		SchemaVersion: 2,
+		EventID:       "evt-1",
+		Timestamp:     time.Date(2024, 6, 1, 12, 30, 45, 0, time.UTC),
+		VideoID:       testVideoID,
+		Action:        models.WatchTimeAction,
+		UserID:        "user-1",
+		WatchTime:     &models.WatchTimePayload{Seconds: 42},
+		Region:        "VN",
+		Locale:        "vi-VN",
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("decoded mismatch:\n got  %+v\n want %+v", event, want)
+	}
+}
+
+func TestDecodeRejectsNewerSchemaVersion(t *testing.T) {
+	value := `{"schema_version":3,"video_id":"6f1c9a3e-8d2b-4c57-9e0a-1b2c3d4e5f60","action":"view","user_id":"user-1"}`
+	if _, err := Decode([]byte(value), nil); err == nil {
+		t.Fatal("Decode accepted a schema version newer than VideoEventSchemaVersion")
+	}
+}
+
+func TestDecodeNegotiatesFormatFromHeader(t *testing.T) {
+	event := testEvent(models.ShareAction)
+	jsonValue, err := Encode(event, FormatJSON)
+	if err != nil {
+		t.Fatalf("Encode JSON: %v", err)
+	}
+	protobufValue, err := Encode(event, FormatProtobuf)
+	if err != nil {
+		t.Fatalf("Encode Protobuf: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   []byte
+		headers []eventbus.Header
+		wantErr bool
+	}{
+		{"no header is JSON", jsonValue, nil, false},
+		{"other headers only is JSON", jsonValue, []eventbus.Header{{Key: "event-id", Value: []byte("evt-1")}}, false},
+		{"JSON header", jsonValue, []eventbus.Header{{Key: ContentTypeHeader, Value: []byte(FormatJSON)}}, false},
+		{"Protobuf header", protobufValue, protobufHeaders(), false},
+		{"Protobuf without header", protobufValue, nil, true},
+		{"JSON with Protobuf header", jsonValue, protobufHeaders(), true},
+		{"unknown content type", jsonValue, []eventbus.Header{{Key: ContentTypeHeader, Value: []byte("application/avro")}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(tt.value, tt.headers)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Decode succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, event) {
+				t.Errorf("decoded mismatch:\n got  %+v\n want %+v", decoded, event)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatJSON, false},
+		{"json", FormatJSON, false},
+		{"protobuf", FormatProtobuf, false},
+		{"avro", "", true},
+	}
+	for _, tt := range tests {
+		format, err := ParseFormat(tt.name)
+		if (err != nil) != tt.wantErr || format != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q, error %v", tt.name, format, err, tt.want, tt.wantErr)
+		}
+	}
+}
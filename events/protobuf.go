@@ -0,0 +1,91 @@
+package events
+
+//go:generate protoc --go_out=eventspb --go_opt=paths=source_relative video_event.proto
+
+import (
+	"errors"
+	"fmt"
+	"realtime-ranking/events/eventspb"
+	"realtime-ranking/models"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// protoActions maps actions to their values of the Action enum.
+var protoActions = map[string]eventspb.Action{
+	models.ViewAction:      eventspb.Action_ACTION_VIEW,
+	models.LikeAction:      eventspb.Action_ACTION_LIKE,
+	models.CommentAction:   eventspb.Action_ACTION_COMMENT,
+	models.ShareAction:     eventspb.Action_ACTION_SHARE,
+	models.WatchTimeAction: eventspb.Action_ACTION_WATCH_TIME,
+}
+
+var errMalformedProtobuf = errors.New("malformed protobuf video event")
+
+func marshalProtobuf(event *models.VideoEvent) ([]byte, error) {
+	action, ok := protoActions[event.Action]
+	if !ok {
+		return nil, fmt.Errorf("cannot encode unknown action %q", event.Action)
+	}
+
+	message := &eventspb.VideoEvent{
+		SchemaVersion: uint32(event.SchemaVersion),
+		EventId:       event.EventID,
+		VideoId:       event.VideoID.String(),
+		Action:        action,
+		UserId:        event.UserID,
+		Region:        event.Region,
+		Locale:        event.Locale,
+	}
+	if !event.Timestamp.IsZero() {
+		message.Timestamp = timestamppb.New(event.Timestamp)
+	}
+	if event.WatchTime != nil {
+		message.Payload = &eventspb.VideoEvent_WatchTime{
+			WatchTime: &eventspb.WatchTimePayload{Seconds: int64(event.WatchTime.Seconds)},
+		}
+	}
+	return proto.Marshal(message)
+}
+
+func unmarshalProtobuf(b []byte) (*models.VideoEvent, error) {
+	var message eventspb.VideoEvent
+	if err := proto.Unmarshal(b, &message); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMalformedProtobuf, err)
+	}
+
+	event := models.VideoEvent{
+		SchemaVersion: int(message.GetSchemaVersion()),
+		EventID:       message.GetEventId(),
+		UserID:        message.GetUserId(),
+		Region:        message.GetRegion(),
+		Locale:        message.GetLocale(),
+	}
+	if message.Timestamp != nil {
+		event.Timestamp = message.Timestamp.AsTime()
+	}
+	// Missing fields are left zero for validation to reject, as with JSON.
+	if message.GetVideoId() != "" {
+		videoID, err := uuid.Parse(message.GetVideoId())
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid video_id %q: %v", errMalformedProtobuf, message.GetVideoId(), err)
+		}
+		event.VideoID = videoID
+	}
+	if message.GetAction() != eventspb.Action_ACTION_UNSPECIFIED {
+		for action, value := range protoActions {
+			if value == message.GetAction() {
+				event.Action = action
+			}
+		}
+		if event.Action == "" {
+			return nil, fmt.Errorf("%w: unknown action %d", errMalformedProtobuf, message.GetAction())
+		}
+	}
+	if watchTime := message.GetWatchTime(); watchTime != nil {
+		event.WatchTime = &models.WatchTimePayload{Seconds: int(watchTime.GetSeconds())}
+	}
+	return &event, nil
+}
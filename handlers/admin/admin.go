@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"realtime-ranking/consumer"
+	"realtime-ranking/handlers/videos"
+	"realtime-ranking/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireToken rejects requests that do not carry "Authorization: Bearer <token>".
+func RequireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, videos.ErrorResponse{Message: "Unauthorized", Details: "A valid admin bearer token is required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+type AdminHandler struct {
+	rankingService *services.RankingService
+	eventConsumer  *consumer.VideoEventConsumer
+}
+
+func NewAdminHandler(rankingService *services.RankingService, eventConsumer *consumer.VideoEventConsumer) *AdminHandler {
+	return &AdminHandler{rankingService: rankingService, eventConsumer: eventConsumer}
+}
+
+// GetScoringConfig godoc
+// @Summary     Get the active scoring config
+// @Description Returns the scoring weights currently applied to events and personalized rankings, along with their version
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} scoring.Config
+// @Failure     401 {object} videos.ErrorResponse
+// @Security    AdminToken
+// @Router      /admin/scoring [get]
+func (ah *AdminHandler) GetScoringConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, ah.rankingService.ScoringConfig())
+}
+
+// ReloadScoringConfig godoc
+// @Summary     Reload the scoring config
+// @Description Re-reads the scoring config file and activates it without a restart. The previous config stays active if the new one is invalid or changes the config without changing its version. When the version changes, every video's all-time score is recomputed with the new config in the background.
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} scoring.Config
+// @Failure     401 {object} videos.ErrorResponse
+// @Failure     500 {object} videos.ErrorResponse
+// @Security    AdminToken
+// @Router      /admin/scoring/reload [post]
+func (ah *AdminHandler) ReloadScoringConfig(c *gin.Context) {
+	config, err := ah.rankingService.ReloadScoringConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, videos.ErrorResponse{Message: "Failed to reload scoring config", Details: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// GetConsumerWorkers godoc
+// @Summary     Get event consumer worker metrics
+// @Description Returns the queue depth, throughput, retries and dead-lettered count of each Kafka consumer worker
+// @Tags        admin
+// @Produce     json
+// @Success     200 {array} consumer.WorkerStats
+// @Failure     401 {object} videos.ErrorResponse
+// @Security    AdminToken
+// @Router      /admin/consumer/workers [get]
+func (ah *AdminHandler) GetConsumerWorkers(c *gin.Context) {
+	c.JSON(http.StatusOK, ah.eventConsumer.WorkerStats())
+}
@@ -0,0 +1,79 @@
+package creators
+
+import (
+	"context"
+	"net/http"
+	"realtime-ranking/handlers/videos"
+	"realtime-ranking/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreatorHandler struct {
+	rankingService *services.RankingService
+}
+
+func NewCreatorHandler(rankingService *services.RankingService) *CreatorHandler {
+	return &CreatorHandler{rankingService: rankingService}
+}
+
+// GetTopCreators godoc
+// @Summary     Get top-ranked creators
+// @Description Retrieve creators ranked by the aggregate score of their videos: the sum of all their video scores, or the mean of their best CREATOR_TOP_K videos when configured
+// @Tags        creators
+// @Produce     json
+// @Param       start query int false "Start index" default(0) minimum(0)
+// @Param       count query int false "Number of creators to retrieve" default(10) minimum(1) maximum(100)
+// @Success     200   {array} models.CreatorScore
+// @Failure     400   {object} videos.ErrorResponse
+// @Failure     500   {object} videos.ErrorResponse
+// @Router      /creators/top [get]
+func (ch *CreatorHandler) GetTopCreators(c *gin.Context) {
+	start, count, ok := videos.ParsePagination(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	creators, err := ch.rankingService.GetTopCreators(ctx, start, start+count-1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, videos.ErrorResponse{Message: "Failed to get top creators", Details: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, creators)
+}
+
+// GetTopCreatorVideos godoc
+// @Summary     Get a creator's top-ranked videos
+// @Description Retrieve a creator's videos ranked by their all-time score
+// @Tags        creators
+// @Produce     json
+// @Param       id    path  string true  "Creator ID"
+// @Param       start query int    false "Start index" default(0) minimum(0)
+// @Param       count query int    false "Number of videos to retrieve" default(10) minimum(1) maximum(100)
+// @Success     200   {array} models.Video
+// @Failure     400   {object} videos.ErrorResponse
+// @Failure     500   {object} videos.ErrorResponse
+// @Router      /creators/{id}/videos/top [get]
+func (ch *CreatorHandler) GetTopCreatorVideos(c *gin.Context) {
+	creatorID := c.Param("id")
+	start, count, ok := videos.ParsePagination(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	creatorVideos, err := ch.rankingService.GetTopCreatorVideos(ctx, creatorID, start, start+count-1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, videos.ErrorResponse{Message: "Failed to get top videos for creator", Details: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, creatorVideos)
+}
@@ -0,0 +1,73 @@
+package creators
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"realtime-ranking/events"
+	"realtime-ranking/models"
+	"realtime-ranking/scoring"
+	"realtime-ranking/services"
+	"realtime-ranking/store"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCreatorLeaderboardPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider, err := scoring.NewProvider("")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ms := store.NewMemoryStore(time.Hour)
+	rankingService := services.NewRankingService(ms, ms, nil, nil, events.FormatJSON, provider, services.LeaderboardConfig{})
+	creatorHandler := NewCreatorHandler(rankingService)
+	router := gin.New()
+	router.GET("/creators/top", creatorHandler.GetTopCreators)
+	router.GET("/creators/:id/videos/top", creatorHandler.GetTopCreatorVideos)
+
+	// creator-1 has three videos; creator-2 and creator-3 have one each.
+	for i, creatorID := range []string{"creator-1", "creator-1", "creator-1", "creator-2", "creator-3"} {
+		video := &models.Video{ID: uuid.New(), Title: "video", CreatorID: creatorID}
+		if err := rankingService.CreateVideo(context.Background(), video); err != nil {
+			t.Fatalf("CreateVideo: %v", err)
+		}
+		if _, err := rankingService.ApplyVideoDelta(context.Background(), models.VideoDelta{VideoID: video.ID, Likes: i + 1}, time.Now()); err != nil {
+			t.Fatalf("ApplyVideoDelta: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantEntries int
+	}{
+		{"first creators", "/creators/top?count=2", 2},
+		{"later creators", "/creators/top?start=1&count=2", 2},
+		{"last creator", "/creators/top?start=2&count=2", 1},
+		{"first videos", "/creators/creator-1/videos/top?count=2", 2},
+		{"later videos", "/creators/creator-1/videos/top?start=1&count=2", 2},
+		{"past the end", "/creators/creator-1/videos/top?start=3", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+			}
+			var entries []json.RawMessage
+			if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if len(entries) != tt.wantEntries {
+				t.Errorf("got %d entries, want %d", len(entries), tt.wantEntries)
+			}
+		})
+	}
+}
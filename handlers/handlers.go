@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"realtime-ranking/models"
@@ -9,77 +10,229 @@ import (
 	"time"
 )
 
+// ErrInvalidEvent is returned (wrapped) for events that can never be processed, such
+// as an unknown action or a malformed value. Retrying them is pointless.
+var ErrInvalidEvent = errors.New("invalid video event")
+
+// DefaultEventDedupeTTL is how long processed event IDs are remembered. It must
+// outlast any realistic producer retry or consumer redelivery.
+const DefaultEventDedupeTTL = 24 * time.Hour
+
+// eventClaimTTL is how long an event ID is held while its batch is being applied. The
+// claim only lasts the dedupe TTL once the batch has been written, so if the process
+// dies mid-batch the redelivered events are not dropped as duplicates for long.
+const eventClaimTTL = time.Minute
+
+// Confirming applied events is retried with exponential backoff from
+// confirmBackoffMin up to confirmBackoffMax until it succeeds or the context ends.
+const (
+	confirmBackoffMin = 100 * time.Millisecond
+	confirmBackoffMax = 5 * time.Second
+)
+
 type VideoEventHandler struct {
 	rankingService *services.RankingService
+	dedupeTTL      time.Duration
 }
 
-func NewVideoEventHandler(rankingService *services.RankingService) *VideoEventHandler {
-	return &VideoEventHandler{rankingService: rankingService}
+func NewVideoEventHandler(rankingService *services.RankingService, dedupeTTL time.Duration) *VideoEventHandler {
+	if dedupeTTL <= 0 {
+		dedupeTTL = DefaultEventDedupeTTL
+	}
+	return &VideoEventHandler{rankingService: rankingService, dedupeTTL: dedupeTTL}
 }
 
+// ValidateVideoEvent reports whether the event can be applied, returning an error
+// wrapping ErrInvalidEvent if not.
+func ValidateVideoEvent(event *models.VideoEvent) error {
+	var delta models.VideoDelta
+	var interaction models.UserVideoInteraction
+	return addEvent(event, &delta, &interaction)
+}
+
+// ProcessVideoEvent applies a single event; see ProcessVideoEvents.
 func (vh *VideoEventHandler) ProcessVideoEvent(ctx context.Context, event *models.VideoEvent) error {
-	video, err := vh.rankingService.GetVideo(ctx, event.VideoID)
+	return vh.ProcessVideoEvents(ctx, []*models.VideoEvent{event})
+}
+
+// ProcessVideoEvents applies a batch of events for one video as a single combined
+// counter increment and leaderboard update. Events carrying an ID that was already
+// processed within the dedupe TTL are dropped; events without an ID (published
+// before IDs were introduced) are always applied. If the batch fails, none of its
+// events are recorded as processed.
+func (vh *VideoEventHandler) ProcessVideoEvents(ctx context.Context, events []*models.VideoEvent) error {
+	_, err := vh.processVideoEvents(ctx, events)
+	return err
+}
+
+// ApplyVideoEvent processes a single event inline, without a message broker, and
+// returns the video as it stands afterwards. A duplicate event is not applied again;
+// the video is returned unchanged.
+func (vh *VideoEventHandler) ApplyVideoEvent(ctx context.Context, event *models.VideoEvent) (*models.Video, error) {
+	video, err := vh.processVideoEvents(ctx, []*models.VideoEvent{event})
 	if err != nil {
-		return fmt.Errorf("error getting video %s: %w", event.VideoID, err)
+		return nil, err
+	}
+	if video == nil {
+		return vh.rankingService.GetVideo(ctx, event.VideoID)
 	}
+	return video, nil
+}
 
-	switch event.Action {
-	case models.ViewAction:
-		video.Views++
-		video.Score += 1
-	case models.LikeAction:
-		video.Likes++
-		video.Score += 5
-	case models.CommentAction:
-		video.Comments++
-		video.Score += 3
-	case models.ShareAction:
-		video.Shares++
-		video.Score += 4
-	case models.WatchTimeAction:
-		watchTime, ok := event.Value.(float64)
-		if !ok {
-			return fmt.Errorf("invalid watch time value: %v", event.Value)
+// processVideoEvents implements ProcessVideoEvents, returning the updated video, or
+// nil if every event was a duplicate.
+func (vh *VideoEventHandler) processVideoEvents(ctx context.Context, events []*models.VideoEvent) (_ *models.Video, err error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	videoID := events[0].VideoID
+
+	var claimed []string
+	defer func() {
+		if err == nil {
+			return
 		}
-		video.WatchTime += int(watchTime)
-		video.Score += watchTime * 0.05 // Reduced weight for watch time
-	default:
-		return fmt.Errorf("unknown action: %s", event.Action)
+		// Let a redelivery retry the events instead of dropping them as duplicates.
+		for _, eventID := range claimed {
+			if releaseErr := vh.rankingService.ReleaseEvent(context.Background(), eventID); releaseErr != nil {
+				log.Printf("Error releasing event %s: %v", eventID, releaseErr)
+			}
+		}
+	}()
+
+	delta := models.VideoDelta{VideoID: videoID}
+	interactions := make(map[string]*models.UserVideoInteraction)
+	var userOrder []string
+	var occurredAt time.Time
+	applied := 0
+	for _, event := range events {
+		if event.VideoID != videoID {
+			return nil, fmt.Errorf("%w: batch for video %s contains event for video %s", ErrInvalidEvent, videoID, event.VideoID)
+		}
+		if event.EventID != "" {
+			isNew, err := vh.rankingService.ClaimEvent(ctx, event.EventID, eventClaimTTL)
+			if err != nil {
+				return nil, err
+			}
+			if !isNew {
+				log.Printf("Skipping duplicate event %s for video %s\n", event.EventID, event.VideoID)
+				continue
+			}
+			claimed = append(claimed, event.EventID)
+		}
+
+		interaction, exists := interactions[event.UserID]
+		if !exists {
+			interaction = &models.UserVideoInteraction{UserID: event.UserID, VideoID: videoID}
+			interactions[event.UserID] = interaction
+			userOrder = append(userOrder, event.UserID)
+		}
+		var eventDelta models.VideoDelta
+		if err := addEvent(event, &eventDelta, interaction); err != nil {
+			return nil, err
+		}
+		delta.Add(eventDelta)
+		for _, audience := range event.Audiences() {
+			if delta.Audiences == nil {
+				delta.Audiences = make(map[models.Audience]models.VideoDelta)
+			}
+			audienceDelta := delta.Audiences[audience]
+			audienceDelta.Add(eventDelta)
+			delta.Audiences[audience] = audienceDelta
+		}
+		if eventTime := eventTimestamp(event); eventTime.After(occurredAt) {
+			occurredAt = eventTime
+		}
+		applied++
+	}
+	if applied == 0 {
+		return nil, nil
 	}
 
-	if err := vh.rankingService.UpdateVideo(ctx, video); err != nil {
-		return fmt.Errorf("error updating video %s: %w", video.ID, err)
+	video, err := vh.rankingService.ApplyVideoDelta(ctx, delta, occurredAt)
+	if err != nil {
+		return nil, fmt.Errorf("error updating video %s: %w", videoID, err)
+	}
+	// The delta has been applied, so the claims must not be released from here on: a
+	// redelivery would apply it twice.
+	confirming := claimed
+	claimed = nil
+	if err := vh.confirmEvents(ctx, confirming); err != nil {
+		return nil, fmt.Errorf("error confirming events for video %s: %w", videoID, err)
 	}
 
 	// Update user interaction history (with error handling)
-	interaction := &models.UserVideoInteraction{
-		UserID:    event.UserID,
-		VideoID:   event.VideoID,
-		LastViewed: time.Now().UTC(),
-		Views:     0,
-		Likes:     0,
-		Comments:  0,
-		Shares:    0,
-		WatchTime: 0,
+	for _, userID := range userOrder {
+		if err := vh.rankingService.UpdateUserVideoInteraction(ctx, interactions[userID]); err != nil {
+			log.Printf("Error updating user video interaction: %v", err)
+		}
+	}
+
+	log.Printf("Processed %d event(s) for video %s: New Score=%.2f, Scoring=%s\n", applied, video.ID, video.Score, video.ScoringVersion)
+	return video, nil
+}
+
+// confirmEvents extends the claims on applied events to the dedupe TTL, retrying
+// until every claim is confirmed. It only gives up when ctx ends, in which case the
+// unconfirmed claims still lapse after eventClaimTTL, and the caller must not treat
+// the events as processed.
+func (vh *VideoEventHandler) confirmEvents(ctx context.Context, eventIDs []string) error {
+	backoff := confirmBackoffMin
+	for len(eventIDs) > 0 {
+		err := vh.rankingService.ConfirmEvent(ctx, eventIDs[0], vh.dedupeTTL)
+		if err == nil {
+			eventIDs = eventIDs[1:]
+			backoff = confirmBackoffMin
+			continue
+		}
+		log.Printf("Error confirming event %s, retrying in %v: %v", eventIDs[0], backoff, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error confirming event %s: %w", eventIDs[0], err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > confirmBackoffMax {
+			backoff = confirmBackoffMax
+		}
 	}
+	return nil
+}
 
+// addEvent adds the event's effect to delta and to the user's interaction.
+func addEvent(event *models.VideoEvent, delta *models.VideoDelta, interaction *models.UserVideoInteraction) error {
 	switch event.Action {
 	case models.ViewAction:
-		interaction.Views = 1
+		delta.Views++
+		interaction.Views++
 	case models.LikeAction:
-		interaction.Likes = 1
+		delta.Likes++
+		interaction.Likes++
 	case models.CommentAction:
-		interaction.Comments = 1
+		delta.Comments++
+		interaction.Comments++
 	case models.ShareAction:
-		interaction.Shares = 1
+		delta.Shares++
+		interaction.Shares++
 	case models.WatchTimeAction:
-		interaction.WatchTime = int(event.Value.(float64))
+		if event.WatchTime == nil || event.WatchTime.Seconds <= 0 {
+			return fmt.Errorf("%w: watch_time event without a positive watch time", ErrInvalidEvent)
+		}
+		delta.WatchTime += event.WatchTime.Seconds
+		interaction.WatchTime += event.WatchTime.Seconds
+	default:
+		return fmt.Errorf("%w: unknown action: %s", ErrInvalidEvent, event.Action)
 	}
 
-	if err := vh.rankingService.UpdateUserVideoInteraction(ctx, interaction); err != nil {
-		log.Printf("Error updating user video interaction: %v", err)
+	if eventTime := eventTimestamp(event); eventTime.After(interaction.LastViewed) {
+		interaction.LastViewed = eventTime
 	}
-
-	log.Printf("Processed event for video %s: Action=%s, New Score=%.2f\n", video.ID, event.Action, video.Score)
 	return nil
-}
\ No newline at end of file
+}
+
+func eventTimestamp(event *models.VideoEvent) time.Time {
+	if event.Timestamp.IsZero() {
+		return time.Now().UTC()
+	}
+	return event.Timestamp.UTC()
+}
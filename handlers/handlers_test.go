@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"realtime-ranking/events"
+	"realtime-ranking/models"
+	"realtime-ranking/scoring"
+	"realtime-ranking/services"
+	"realtime-ranking/store"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestHandler(t *testing.T) (*VideoEventHandler, *services.RankingService, uuid.UUID) {
+	t.Helper()
+	provider, err := scoring.NewProvider("")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ms := store.NewMemoryStore(time.Hour)
+	rankingService := services.NewRankingService(ms, ms, nil, nil, events.FormatJSON, provider, services.LeaderboardConfig{})
+	video := &models.Video{ID: uuid.New(), Title: "dedupe"}
+	if err := rankingService.CreateVideo(context.Background(), video); err != nil {
+		t.Fatalf("CreateVideo: %v", err)
+	}
+	return NewVideoEventHandler(rankingService, time.Minute), rankingService, video.ID
+}
+
+func TestProcessVideoEventDedupe(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventIDs  []string
+		wantViews int
+	}{
+		{"distinct events", []string{"a", "b", "c"}, 3},
+		{"redelivered event", []string{"a", "a", "b"}, 2},
+		{"events without IDs", []string{"", "", ""}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			handler, rankingService, videoID := newTestHandler(t)
+			for _, eventID := range tt.eventIDs {
+				event := &models.VideoEvent{EventID: eventID, VideoID: videoID, UserID: "user-1", Action: models.ViewAction}
+				if err := handler.ProcessVideoEvent(ctx, event); err != nil {
+					t.Fatalf("ProcessVideoEvent(%q): %v", eventID, err)
+				}
+			}
+			video, err := rankingService.GetVideo(ctx, videoID)
+			if err != nil {
+				t.Fatalf("GetVideo: %v", err)
+			}
+			if video.Views != tt.wantViews {
+				t.Errorf("views = %d, want %d", video.Views, tt.wantViews)
+			}
+		})
+	}
+}
+
+func TestProcessVideoEventReleasesFailedEvent(t *testing.T) {
+	ctx := context.Background()
+	handler, rankingService, videoID := newTestHandler(t)
+
+	event := &models.VideoEvent{EventID: "watch-1", VideoID: videoID, UserID: "user-1", Action: models.WatchTimeAction, WatchTime: &models.WatchTimePayload{}}
+	if err := handler.ProcessVideoEvent(ctx, event); err == nil {
+		t.Fatal("ProcessVideoEvent accepted a watch time of zero")
+	}
+
+	// The failed attempt must not leave a claim behind, or the redelivery would be dropped.
+	event.WatchTime.Seconds = 10
+	if err := handler.ProcessVideoEvent(ctx, event); err != nil {
+		t.Fatalf("ProcessVideoEvent redelivery: %v", err)
+	}
+	video, err := rankingService.GetVideo(ctx, videoID)
+	if err != nil {
+		t.Fatalf("GetVideo: %v", err)
+	}
+	if video.WatchTime != 10 {
+		t.Errorf("watch time = %d, want 10", video.WatchTime)
+	}
+}
+
+func TestProcessVideoEventsBatch(t *testing.T) {
+	ctx := context.Background()
+	handler, rankingService, videoID := newTestHandler(t)
+	view := func(eventID string) *models.VideoEvent {
+		return &models.VideoEvent{EventID: eventID, VideoID: videoID, UserID: "user-1", Action: models.ViewAction}
+	}
+
+	// An invalid event fails the whole batch and releases the claims it made.
+	invalid := &models.VideoEvent{EventID: "bad", VideoID: videoID, UserID: "user-1", Action: "rate"}
+	if err := handler.ProcessVideoEvents(ctx, []*models.VideoEvent{view("a"), invalid}); err == nil {
+		t.Fatal("ProcessVideoEvents accepted an unknown action")
+	}
+	if err := handler.ProcessVideoEvents(ctx, []*models.VideoEvent{view("a"), view("a"), view("b")}); err != nil {
+		t.Fatalf("ProcessVideoEvents: %v", err)
+	}
+
+	video, err := rankingService.GetVideo(ctx, videoID)
+	if err != nil {
+		t.Fatalf("GetVideo: %v", err)
+	}
+	if video.Views != 2 {
+		t.Errorf("views = %d, want 2", video.Views)
+	}
+}
+
+func TestApplyVideoEvent(t *testing.T) {
+	ctx := context.Background()
+	handler, _, videoID := newTestHandler(t)
+	like := &models.VideoEvent{EventID: "like-1", VideoID: videoID, UserID: "user-1", Action: models.LikeAction}
+
+	video, err := handler.ApplyVideoEvent(ctx, like)
+	if err != nil {
+		t.Fatalf("ApplyVideoEvent: %v", err)
+	}
+	if video.Likes != 1 || video.Score <= 0 {
+		t.Fatalf("ApplyVideoEvent = %d likes and score %v, want 1 like and a positive score", video.Likes, video.Score)
+	}
+
+	// A duplicate returns the video as it stands without applying the event again.
+	duplicate, err := handler.ApplyVideoEvent(ctx, like)
+	if err != nil {
+		t.Fatalf("ApplyVideoEvent duplicate: %v", err)
+	}
+	if duplicate.Likes != 1 || duplicate.Score != video.Score {
+		t.Errorf("ApplyVideoEvent duplicate = %d likes and score %v, want %d and %v", duplicate.Likes, duplicate.Score, 1, video.Score)
+	}
+
+	missing := &models.VideoEvent{VideoID: uuid.New(), UserID: "user-1", Action: models.LikeAction}
+	if _, err := handler.ApplyVideoEvent(ctx, missing); !errors.Is(err, services.ErrVideoNotFound) {
+		t.Errorf("ApplyVideoEvent for an unknown video = %v, want %v", err, services.ErrVideoNotFound)
+	}
+}
+
+func TestProcessVideoEventConfirmsWithDedupeTTL(t *testing.T) {
+	ctx := context.Background()
+	handler, rankingService, videoID := newTestHandler(t)
+	handler.dedupeTTL = 50 * time.Millisecond
+	view := &models.VideoEvent{EventID: "view-1", VideoID: videoID, UserID: "user-1", Action: models.ViewAction}
+
+	// Once applied, the event is remembered for the dedupe TTL rather than the claim TTL.
+	if err := handler.ProcessVideoEvent(ctx, view); err != nil {
+		t.Fatalf("ProcessVideoEvent: %v", err)
+	}
+	if err := handler.ProcessVideoEvent(ctx, view); err != nil {
+		t.Fatalf("ProcessVideoEvent duplicate: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := handler.ProcessVideoEvent(ctx, view); err != nil {
+		t.Fatalf("ProcessVideoEvent after the dedupe TTL: %v", err)
+	}
+
+	video, err := rankingService.GetVideo(ctx, videoID)
+	if err != nil {
+		t.Fatalf("GetVideo: %v", err)
+	}
+	if video.Views != 2 {
+		t.Errorf("views = %d, want 2", video.Views)
+	}
+}
+
+// flakyConfirmStore fails the first failures calls to ConfirmEvent.
+type flakyConfirmStore struct {
+	*store.MemoryStore
+	failures int
+}
+
+func (fs *flakyConfirmStore) ConfirmEvent(ctx context.Context, eventID string, ttl time.Duration) error {
+	if fs.failures != 0 {
+		fs.failures--
+		return errors.New("confirm failed")
+	}
+	return fs.MemoryStore.ConfirmEvent(ctx, eventID, ttl)
+}
+
+func TestProcessVideoEventRetriesConfirm(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		timeout   time.Duration
+		wantErr   bool
+		wantViews int
+	}{
+		{"confirmed after retries", 2, time.Minute, false, 1},
+		{"never confirmed", -1, 250 * time.Millisecond, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := scoring.NewProvider("")
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			ms := store.NewMemoryStore(time.Hour)
+			index := &flakyConfirmStore{MemoryStore: ms, failures: tt.failures}
+			rankingService := services.NewRankingService(ms, index, nil, nil, events.FormatJSON, provider, services.LeaderboardConfig{})
+			video := &models.Video{ID: uuid.New(), Title: "confirm"}
+			if err := rankingService.CreateVideo(context.Background(), video); err != nil {
+				t.Fatalf("CreateVideo: %v", err)
+			}
+			handler := NewVideoEventHandler(rankingService, time.Minute)
+			view := &models.VideoEvent{EventID: "view-1", VideoID: video.ID, UserID: "user-1", Action: models.ViewAction}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			if err := handler.ProcessVideoEvent(ctx, view); (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessVideoEvent = %v, want error %v", err, tt.wantErr)
+			}
+			// Either way the event has been applied, so its claim must still be held and
+			// a redelivery dropped as a duplicate.
+			if err := handler.ProcessVideoEvent(context.Background(), view); err != nil {
+				t.Fatalf("ProcessVideoEvent redelivery: %v", err)
+			}
+			stored, err := rankingService.GetVideo(context.Background(), video.ID)
+			if err != nil {
+				t.Fatalf("GetVideo: %v", err)
+			}
+			if stored.Views != tt.wantViews {
+				t.Errorf("views = %d, want %d", stored.Views, tt.wantViews)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package videos
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"realtime-ranking/handlers"
 	"realtime-ranking/models"
 	"realtime-ranking/services"
 	"strconv"
@@ -13,18 +16,31 @@ import (
 	"github.com/google/uuid"
 )
 
+// idempotencyKeyHeader lets clients supply their own event ID so retried requests
+// are only counted once.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// leaderboardHeader tells region and language queries which leaderboard was served,
+// since small ones fall back to the global leaderboard.
+const leaderboardHeader = "X-Leaderboard"
+
 type VideoHandler struct {
 	rankingService *services.RankingService
 	validate       *validator.Validate
+	// eventHandler, when set, applies events inline instead of publishing them, and
+	// event endpoints respond with the updated score and rank.
+	eventHandler *handlers.VideoEventHandler
 }
 
-func NewVideoHandler(rankingService *services.RankingService, validate *validator.Validate) *VideoHandler {
-	return &VideoHandler{rankingService: rankingService, validate: validate}
+// NewVideoHandler creates the handler. eventHandler is optional: when nil, events are
+// published with PublishVideoEvent and processed asynchronously by the consumer.
+func NewVideoHandler(rankingService *services.RankingService, validate *validator.Validate, eventHandler *handlers.VideoEventHandler) *VideoHandler {
+	return &VideoHandler{rankingService: rankingService, validate: validate, eventHandler: eventHandler}
 }
 
 // CreateVideo godoc
 // @Summary     Create a new video
-// @Description Creates a new video with the given title and Base64 encoded data
+// @Description Creates a new video with the given title, Base64 encoded data and optional creator, language, duration, categories and tags. Categories and tags are trimmed, lower-cased and de-duplicated.
 // @Tags        videos
 // @Accept      json
 // @Produce     json
@@ -46,9 +62,14 @@ func (vh *VideoHandler) CreateVideo(c *gin.Context) {
 	}
 
 	newVideo := models.Video{
-		ID:    uuid.New(),
-		Title: video.Title,
-		Data:  video.Data,
+		ID:              uuid.New(),
+		Title:           video.Title,
+		Data:            video.Data,
+		CreatorID:       video.CreatorID,
+		Language:        video.Language,
+		DurationSeconds: video.DurationSeconds,
+		Categories:      models.NormalizeLabels(video.Categories),
+		Tags:            models.NormalizeLabels(video.Tags),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -64,17 +85,19 @@ func (vh *VideoHandler) CreateVideo(c *gin.Context) {
 }
 
 // UpdateVideo godoc
-// @Summary     Update video
-// @Description Updates a video's title and Base64 encoded data
+// @Summary     Update video metadata
+// @Description Partially updates a video's title, Base64 encoded data, language, duration, categories and tags. Omitted fields are left unchanged, categories and tags replace the current lists when present, and engagement counters and ranking are never modified.
 // @Tags        videos
 // @Accept      json
 // @Produce     json
 // @Param       id    path   string                true "Video ID"
-// @Param       video body models.UpdateVideoRequest true "Video object to be updated"
+// @Param       video body models.UpdateVideoRequest true "Metadata fields to update"
 // @Success     200 {object} models.Video
 // @Failure     400 {object} ErrorResponse
+// @Failure     404 {object} ErrorResponse
 // @Failure     500 {object} ErrorResponse
 // @Router      /videos/{id} [put]
+// @Router      /videos/{id} [patch]
 func (vh *VideoHandler) UpdateVideo(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -94,17 +117,34 @@ func (vh *VideoHandler) UpdateVideo(c *gin.Context) {
 		return
 	}
 
-	updatedVideo := models.Video{
-		ID:    id,
-		Title: updateRequest.Title,
-		Data:  updateRequest.Data,
+	update := models.VideoMetadataUpdate{
+		Title:           updateRequest.Title,
+		Data:            updateRequest.Data,
+		Language:        updateRequest.Language,
+		DurationSeconds: updateRequest.DurationSeconds,
+	}
+	if updateRequest.Categories != nil {
+		categories := models.NormalizeLabels(*updateRequest.Categories)
+		update.Categories = &categories
+	}
+	if updateRequest.Tags != nil {
+		tags := models.NormalizeLabels(*updateRequest.Tags)
+		update.Tags = &tags
+	}
+	if update.IsEmpty() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Validation error", Details: "At least one field must be provided"})
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err = vh.rankingService.UpdateVideo(ctx, &updatedVideo)
+	updatedVideo, err := vh.rankingService.UpdateVideo(ctx, id, update)
 	if err != nil {
+		if errors.Is(err, services.ErrVideoNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "Video not found", Details: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to update video", Details: err.Error()})
 		return
 	}
@@ -120,8 +160,12 @@ func (vh *VideoHandler) UpdateVideo(c *gin.Context) {
 // @Produce     json
 // @Param       id     path   string true "Video ID"
 // @Param       userID query  string true "User ID"
-// @Success     200    {object} SuccessResponse
+// @Param       region query  string false "ISO 3166-1 alpha-2 region the event came from"
+// @Param       locale query  string false "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted"
+// @Param       Idempotency-Key header string false "Client-assigned event ID; repeated submissions with the same key are counted once"
+// @Success     200    {object} EventResponse
 // @Failure     400    {object} ErrorResponse
+// @Failure     404    {object} ErrorResponse
 // @Failure     500    {object} ErrorResponse
 // @Router      /videos/{id}/view [post]
 func (vh *VideoHandler) HandleView(c *gin.Context) {
@@ -142,18 +186,13 @@ func (vh *VideoHandler) HandleView(c *gin.Context) {
 	defer cancel()
 
 	event := &models.VideoEvent{
+		EventID: c.GetHeader(idempotencyKeyHeader),
 		VideoID: id,
 		Action:  models.ViewAction,
 		UserID:  userID,
 	}
 
-	err = vh.rankingService.PublishVideoEvent(ctx, event)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to record view", Details: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, SuccessResponse{Message: "View recorded successfully"})
+	vh.recordEvent(c, ctx, event, "View recorded successfully", "Failed to record view")
 }
 
 // HandleLike godoc
@@ -164,8 +203,12 @@ func (vh *VideoHandler) HandleView(c *gin.Context) {
 // @Produce     json
 // @Param       id     path   string true "Video ID"
 // @Param       userID query  string true "User ID"
-// @Success     200    {object} SuccessResponse
+// @Param       region query  string false "ISO 3166-1 alpha-2 region the event came from"
+// @Param       locale query  string false "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted"
+// @Param       Idempotency-Key header string false "Client-assigned event ID; repeated submissions with the same key are counted once"
+// @Success     200    {object} EventResponse
 // @Failure     400    {object} ErrorResponse
+// @Failure     404    {object} ErrorResponse
 // @Failure     500    {object} ErrorResponse
 // @Router      /videos/{id}/like [post]
 func (vh *VideoHandler) HandleLike(c *gin.Context) {
@@ -186,18 +229,13 @@ func (vh *VideoHandler) HandleLike(c *gin.Context) {
 	defer cancel()
 
 	event := &models.VideoEvent{
+		EventID: c.GetHeader(idempotencyKeyHeader),
 		VideoID: id,
 		Action:  models.LikeAction,
 		UserID:  userID,
 	}
 
-	err = vh.rankingService.PublishVideoEvent(ctx, event)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to record like", Details: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, SuccessResponse{Message: "Like recorded successfully"})
+	vh.recordEvent(c, ctx, event, "Like recorded successfully", "Failed to record like")
 }
 
 // HandleComment godoc
@@ -208,8 +246,12 @@ func (vh *VideoHandler) HandleLike(c *gin.Context) {
 // @Produce     json
 // @Param       id     path   string true "Video ID"
 // @Param       userID query  string true "User ID"
-// @Success     200    {object} SuccessResponse
+// @Param       region query  string false "ISO 3166-1 alpha-2 region the event came from"
+// @Param       locale query  string false "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted"
+// @Param       Idempotency-Key header string false "Client-assigned event ID; repeated submissions with the same key are counted once"
+// @Success     200    {object} EventResponse
 // @Failure     400    {object} ErrorResponse
+// @Failure     404    {object} ErrorResponse
 // @Failure     500    {object} ErrorResponse
 // @Router      /videos/{id}/comment [post]
 func (vh *VideoHandler) HandleComment(c *gin.Context) {
@@ -230,18 +272,13 @@ func (vh *VideoHandler) HandleComment(c *gin.Context) {
 	defer cancel()
 
 	event := &models.VideoEvent{
+		EventID: c.GetHeader(idempotencyKeyHeader),
 		VideoID: id,
 		Action:  models.CommentAction,
 		UserID:  userID,
 	}
 
-	err = vh.rankingService.PublishVideoEvent(ctx, event)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to record comment", Details: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, SuccessResponse{Message: "Comment recorded successfully"})
+	vh.recordEvent(c, ctx, event, "Comment recorded successfully", "Failed to record comment")
 }
 
 // HandleShare godoc
@@ -252,8 +289,12 @@ func (vh *VideoHandler) HandleComment(c *gin.Context) {
 // @Produce     json
 // @Param       id     path   string true "Video ID"
 // @Param       userID query  string true "User ID"
-// @Success     200    {object} SuccessResponse
+// @Param       region query  string false "ISO 3166-1 alpha-2 region the event came from"
+// @Param       locale query  string false "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted"
+// @Param       Idempotency-Key header string false "Client-assigned event ID; repeated submissions with the same key are counted once"
+// @Success     200    {object} EventResponse
 // @Failure     400    {object} ErrorResponse
+// @Failure     404    {object} ErrorResponse
 // @Failure     500    {object} ErrorResponse
 // @Router      /videos/{id}/share [post]
 func (vh *VideoHandler) HandleShare(c *gin.Context) {
@@ -274,18 +315,13 @@ func (vh *VideoHandler) HandleShare(c *gin.Context) {
 	defer cancel()
 
 	event := &models.VideoEvent{
+		EventID: c.GetHeader(idempotencyKeyHeader),
 		VideoID: id,
 		Action:  models.ShareAction,
 		UserID:  userID,
 	}
 
-	err = vh.rankingService.PublishVideoEvent(ctx, event)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to record share", Details: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, SuccessResponse{Message: "Share recorded successfully"})
+	vh.recordEvent(c, ctx, event, "Share recorded successfully", "Failed to record share")
 }
 
 // WatchTimeAction handles the event when a user watches a video for a certain duration.
@@ -297,8 +333,12 @@ func (vh *VideoHandler) HandleShare(c *gin.Context) {
 // @Param       id     path   string true "Video ID"
 // @Param       userID query  string true "User ID"
 // @Param       duration query  string true "Duration"
-// @Success     200    {object} SuccessResponse
+// @Param       region query  string false "ISO 3166-1 alpha-2 region the event came from"
+// @Param       locale query  string false "Viewer's BCP 47 locale, such as vi-VN; also sets the region if region is omitted"
+// @Param       Idempotency-Key header string false "Client-assigned event ID; repeated submissions with the same key are counted once"
+// @Success     200    {object} EventResponse
 // @Failure     400    {object} ErrorResponse
+// @Failure     404    {object} ErrorResponse
 // @Failure     500    {object} ErrorResponse
 // @Router /videos/{id}/watch [post]
 func (vh *VideoHandler) HandleWatch(c *gin.Context) {
@@ -331,38 +371,343 @@ func (vh *VideoHandler) HandleWatch(c *gin.Context) {
 	defer cancel()
 
 	event := &models.VideoEvent{
-		VideoID:  id,
-		Action:   models.WatchTimeAction,
-		UserID:   userID,
-		Value: duration, // Include the duration in the event
+		EventID:   c.GetHeader(idempotencyKeyHeader),
+		VideoID:   id,
+		Action:    models.WatchTimeAction,
+		UserID:    userID,
+		WatchTime: &models.WatchTimePayload{Seconds: duration},
+	}
+
+	vh.recordEvent(c, ctx, event, "Watch recorded successfully", "Failed to record watch")
+}
+
+// recordEvent publishes the event, or applies it inline when the handler has an event
+// handler, and writes the response. The event's optional region and locale are taken
+// from the query string.
+func (vh *VideoHandler) recordEvent(c *gin.Context, ctx context.Context, event *models.VideoEvent, successMessage, failureMessage string) {
+	if regionStr := c.Query("region"); regionStr != "" {
+		region, err := models.ParseRegion(regionStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid region", Details: err.Error()})
+			return
+		}
+		event.Region = region
+	}
+	if localeStr := c.Query("locale"); localeStr != "" {
+		locale, err := models.ParseLocale(localeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid locale", Details: err.Error()})
+			return
+		}
+		event.Locale = locale
+	}
+
+	if vh.eventHandler == nil {
+		if err := vh.rankingService.PublishVideoEvent(ctx, event); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Message: failureMessage, Details: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, EventResponse{Message: successMessage})
+		return
 	}
 
-	err = vh.rankingService.PublishVideoEvent(ctx, event)
+	video, err := vh.eventHandler.ApplyVideoEvent(ctx, event)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to record watch", Details: err.Error()})
+		switch {
+		case errors.Is(err, handlers.ErrInvalidEvent):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: failureMessage, Details: err.Error()})
+		case errors.Is(err, services.ErrVideoNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "Video not found", Details: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Message: failureMessage, Details: err.Error()})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, SuccessResponse{Message: "Watch recorded successfully"})
+	response := EventResponse{Message: successMessage, Score: &video.Score}
+	rank, err := vh.rankingService.GetVideoRank(ctx, video.ID)
+	if err == nil {
+		response.Rank = &rank.Rank
+	} else if !errors.Is(err, services.ErrVideoNotRanked) {
+		// The event has been applied; only the rank is missing from the response.
+		log.Printf("Error getting rank of video %s: %v", video.ID, err)
+	}
+	c.JSON(http.StatusOK, response)
 }
+
 // GetTopVideos godoc
 // @Summary     Get top-ranked videos
 // @Description Retrieve the top-ranked videos
 // @Tags        videos
 // @Produce     json
-// @Param       start query int false "Start index"
-// @Param       count query int false "Number of videos to retrieve"
+// @Param       start query int false "Start index" default(0) minimum(0)
+// @Param       count query int false "Number of videos to retrieve" default(10) minimum(1) maximum(100)
+// @Param       mode  query string false "Ranking mode" Enums(all_time, hot) default(all_time)
+// @Param       window query string false "Rolling time window; when set, videos are ranked by score gained within the window and mode must be omitted" Enums(1h, 24h, 7d)
+// @Param       region query string false "ISO 3166-1 alpha-2 region; when set, videos are ranked by score gained from events in the region, falling back to the global leaderboard if too few videos are ranked there"
+// @Param       language query string false "ISO 639 language; when set, videos are ranked by score gained from events with the language's locale, falling back to the global leaderboard if too few videos are ranked there"
 // @Success     200   {array} models.Video
+// @Header      200   {string} X-Leaderboard "For region and language queries, the leaderboard served: region:<code>, language:<code> or global"
+// @Failure     400   {object} ErrorResponse
 // @Failure     500   {object} ErrorResponse
 // @Router      /videos/top [get]
 func (vh *VideoHandler) GetTopVideos(c *gin.Context) {
-	start, _ := strconv.ParseInt(c.DefaultQuery("start", "0"), 10, 64)
-	count, _ := strconv.ParseInt(c.DefaultQuery("count", "10"), 10, 64)
+	start, count, ok := ParsePagination(c)
+	if !ok {
+		return
+	}
+
+	mode, err := models.ParseRankingMode(c.Query("mode"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid ranking mode", Details: err.Error()})
+		return
+	}
+
+	var window models.RankingWindow
+	if windowStr := c.Query("window"); windowStr != "" {
+		if c.Query("mode") != "" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid query", Details: "mode and window cannot be combined"})
+			return
+		}
+		window, err = models.ParseRankingWindow(windowStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid ranking window", Details: err.Error()})
+			return
+		}
+	}
+
+	var audience *models.Audience
+	if regionStr, languageStr := c.Query("region"), c.Query("language"); regionStr != "" || languageStr != "" {
+		if c.Query("mode") != "" || window != "" || (regionStr != "" && languageStr != "") {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid query", Details: "region and language cannot be combined with each other, mode or window"})
+			return
+		}
+		if regionStr != "" {
+			region, err := models.ParseRegion(regionStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid region", Details: err.Error()})
+				return
+			}
+			audience = &models.Audience{Kind: models.AudienceKindRegion, Name: region}
+		} else {
+			language, err := models.ParseLanguage(languageStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid language", Details: err.Error()})
+				return
+			}
+			audience = &models.Audience{Kind: models.AudienceKindLanguage, Name: language}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var videos []models.Video
+	if audience != nil {
+		var global bool
+		videos, global, err = vh.rankingService.GetTopVideosForAudience(ctx, *audience, start, start+count-1)
+		if global {
+			c.Header(leaderboardHeader, "global")
+		} else {
+			c.Header(leaderboardHeader, audience.String())
+		}
+	} else if window != "" {
+		videos, err = vh.rankingService.GetTopVideosInWindow(ctx, window, start, start+count-1)
+	} else {
+		videos, err = vh.rankingService.GetTopVideos(ctx, mode, start, start+count-1)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to get top videos", Details: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, videos)
+}
+
+// GetVideoRank godoc
+// @Summary     Get a video's rank
+// @Description Returns the video's 0-based position in the all-time leaderboard, its score, the number of ranked videos and the percentage of them ranked at or below it
+// @Tags        videos
+// @Produce     json
+// @Param       id  path string true "Video ID"
+// @Success     200 {object} models.VideoRank
+// @Failure     400 {object} ErrorResponse
+// @Failure     404 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /videos/{id}/rank [get]
+func (vh *VideoHandler) GetVideoRank(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid video ID", Details: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rank, err := vh.rankingService.GetVideoRank(ctx, id)
+	if err != nil {
+		writeRankError(c, err, "Failed to get video rank")
+		return
+	}
+
+	c.JSON(http.StatusOK, rank)
+}
+
+// maxNeighborRadius caps how many videos above and below a video can be requested.
+const maxNeighborRadius = 50
+
+// GetVideoNeighbors godoc
+// @Summary     Get the videos ranked around a video
+// @Description Returns the video's rank along with up to radius videos ranked directly above and below it in the all-time leaderboard
+// @Tags        videos
+// @Produce     json
+// @Param       id     path  string true  "Video ID"
+// @Param       radius query int    false "Number of videos on each side" default(5) minimum(0) maximum(50)
+// @Success     200    {object} models.VideoNeighbors
+// @Failure     400    {object} ErrorResponse
+// @Failure     404    {object} ErrorResponse
+// @Failure     500    {object} ErrorResponse
+// @Router      /videos/{id}/neighbors [get]
+func (vh *VideoHandler) GetVideoNeighbors(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid video ID", Details: err.Error()})
+		return
+	}
+
+	radius, err := strconv.ParseInt(c.DefaultQuery("radius", "5"), 10, 64)
+	if err != nil || radius < 0 || radius > maxNeighborRadius {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid radius", Details: "Radius must be an integer between 0 and " + strconv.Itoa(maxNeighborRadius)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	neighbors, err := vh.rankingService.GetVideoNeighbors(ctx, id, radius)
+	if err != nil {
+		writeRankError(c, err, "Failed to get video neighbors")
+		return
+	}
+
+	c.JSON(http.StatusOK, neighbors)
+}
+
+// writeRankError maps errors from rank lookups to responses.
+func writeRankError(c *gin.Context, err error, failureMessage string) {
+	switch {
+	case errors.Is(err, services.ErrVideoNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Video not found", Details: err.Error()})
+	case errors.Is(err, services.ErrVideoNotRanked):
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Video is not ranked", Details: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: failureMessage, Details: err.Error()})
+	}
+}
+
+// maxUnionLabels caps how many leaderboards a single union query may merge.
+const maxUnionLabels = 20
+
+// MaxPageCount caps how many entries a leaderboard listing may return at once.
+const MaxPageCount = 100
+
+// ParsePagination reads the start and count query parameters of a leaderboard listing.
+// If either is malformed or out of range it responds with 400 and returns false.
+func ParsePagination(c *gin.Context) (start, count int64, ok bool) {
+	start, startErr := strconv.ParseInt(c.DefaultQuery("start", "0"), 10, 64)
+	count, countErr := strconv.ParseInt(c.DefaultQuery("count", "10"), 10, 64)
+	if startErr != nil || countErr != nil || start < 0 || count < 1 || count > MaxPageCount {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid pagination", Details: "start must be a non-negative integer and count an integer between 1 and " + strconv.Itoa(MaxPageCount)})
+		return 0, 0, false
+	}
+	return start, count, true
+}
+
+// GetTopVideosInCategory godoc
+// @Summary     Get top-ranked videos in a category
+// @Description Retrieve the videos in a category ranked by their all-time score. Category names are matched case-insensitively.
+// @Tags        categories
+// @Produce     json
+// @Param       name  path  string true  "Category name"
+// @Param       start query int    false "Start index" default(0) minimum(0)
+// @Param       count query int    false "Number of videos to retrieve" default(10) minimum(1) maximum(100)
+// @Success     200   {array} models.Video
+// @Failure     400   {object} ErrorResponse
+// @Failure     500   {object} ErrorResponse
+// @Router      /categories/{name}/videos/top [get]
+func (vh *VideoHandler) GetTopVideosInCategory(c *gin.Context) {
+	vh.getTopVideosForLabels(c, models.LabelKindCategory, []string{c.Param("name")})
+}
+
+// GetTopVideosInCategories godoc
+// @Summary     Get top-ranked videos across categories
+// @Description Retrieve the videos in any of the given categories ranked by their all-time score. A video in several of the categories is listed once.
+// @Tags        categories
+// @Produce     json
+// @Param       name  query []string true  "Category names (repeat for each category)" collectionFormat(multi)
+// @Param       start query int      false "Start index" default(0) minimum(0)
+// @Param       count query int      false "Number of videos to retrieve" default(10) minimum(1) maximum(100)
+// @Success     200   {array} models.Video
+// @Failure     400   {object} ErrorResponse
+// @Failure     500   {object} ErrorResponse
+// @Router      /categories/videos/top [get]
+func (vh *VideoHandler) GetTopVideosInCategories(c *gin.Context) {
+	vh.getTopVideosForLabels(c, models.LabelKindCategory, c.QueryArray("name"))
+}
+
+// GetTopVideosWithTag godoc
+// @Summary     Get top-ranked videos with a tag
+// @Description Retrieve the videos with a tag ranked by their all-time score. Only available when TAG_LEADERBOARDS is enabled.
+// @Tags        tags
+// @Produce     json
+// @Param       name  path  string true  "Tag name"
+// @Param       start query int    false "Start index" default(0) minimum(0)
+// @Param       count query int    false "Number of videos to retrieve" default(10) minimum(1) maximum(100)
+// @Success     200   {array} models.Video
+// @Failure     400   {object} ErrorResponse
+// @Failure     500   {object} ErrorResponse
+// @Router      /tags/{name}/videos/top [get]
+func (vh *VideoHandler) GetTopVideosWithTag(c *gin.Context) {
+	vh.getTopVideosForLabels(c, models.LabelKindTag, []string{c.Param("name")})
+}
+
+// GetTopVideosWithTags godoc
+// @Summary     Get top-ranked videos across tags
+// @Description Retrieve the videos with any of the given tags ranked by their all-time score. Only available when TAG_LEADERBOARDS is enabled.
+// @Tags        tags
+// @Produce     json
+// @Param       name  query []string true  "Tag names (repeat for each tag)" collectionFormat(multi)
+// @Param       start query int      false "Start index" default(0) minimum(0)
+// @Param       count query int      false "Number of videos to retrieve" default(10) minimum(1) maximum(100)
+// @Success     200   {array} models.Video
+// @Failure     400   {object} ErrorResponse
+// @Failure     500   {object} ErrorResponse
+// @Router      /tags/videos/top [get]
+func (vh *VideoHandler) GetTopVideosWithTags(c *gin.Context) {
+	vh.getTopVideosForLabels(c, models.LabelKindTag, c.QueryArray("name"))
+}
+
+func (vh *VideoHandler) getTopVideosForLabels(c *gin.Context, kind models.LabelKind, names []string) {
+	start, count, ok := ParsePagination(c)
+	if !ok {
+		return
+	}
+
+	labels := models.NormalizeLabels(names)
+	if len(labels) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid query", Details: "at least one " + string(kind) + " name is required"})
+		return
+	}
+	if len(labels) > maxUnionLabels {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid query", Details: "at most " + strconv.Itoa(maxUnionLabels) + " " + string(kind) + " names can be combined"})
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	videos, err := vh.rankingService.GetTopVideos(ctx, start, count-1)
+	videos, err := vh.rankingService.GetTopVideosForLabels(ctx, kind, labels, start, start+count-1)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to get top videos", Details: err.Error()})
 		return
@@ -377,20 +722,23 @@ func (vh *VideoHandler) GetTopVideos(c *gin.Context) {
 // @Tags        users
 // @Produce     json
 // @Param       userID path   string true "User ID"
-// @Param       start  query  int    false "Start index"
-// @Param       count  query  int    false "Number of videos to retrieve"
+// @Param       start  query  int    false "Start index" default(0) minimum(0)
+// @Param       count  query  int    false "Number of videos to retrieve" default(10) minimum(1) maximum(100)
 // @Success     200    {array} models.Video
+// @Failure     400    {object} ErrorResponse
 // @Failure     500    {object} ErrorResponse
 // @Router      /users/{userID}/videos/top [get]
 func (vh *VideoHandler) GetTopVideosPerUser(c *gin.Context) {
 	userID := c.Param("userID")
-	start, _ := strconv.ParseInt(c.DefaultQuery("start", "0"), 10, 64)
-	count, _ := strconv.ParseInt(c.DefaultQuery("count", "10"), 10, 64)
+	start, count, ok := ParsePagination(c)
+	if !ok {
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Longer timeout for personalization
 	defer cancel()
 
-	videos, err := vh.rankingService.GetTopVideosPerUser(ctx, userID, start, count-1)
+	videos, err := vh.rankingService.GetTopVideosPerUser(ctx, userID, start, start+count-1)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to get top videos for user", Details: err.Error()})
 		return
@@ -449,7 +797,16 @@ type ErrorResponse struct {
 	Details string `json:"details,omitempty"`
 }
 
+// EventResponse is returned by the event endpoints. Score and Rank are only set when
+// events are applied inline (INGEST_MODE=direct); Rank is the 0-based position in the
+// all-time leaderboard.
+type EventResponse struct {
+	Message string   `json:"message"`
+	Score   *float64 `json:"score,omitempty"`
+	Rank    *int64   `json:"rank,omitempty"`
+}
+
 // SuccessResponse is a generic success response.
 type SuccessResponse struct {
 	Message string `json:"message"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,158 @@
+package videos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"realtime-ranking/events"
+	"realtime-ranking/handlers"
+	"realtime-ranking/models"
+	"realtime-ranking/scoring"
+	"realtime-ranking/services"
+	"realtime-ranking/store"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+func TestHandleLikeDirectIngest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider, err := scoring.NewProvider("")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ms := store.NewMemoryStore(time.Hour)
+	rankingService := services.NewRankingService(ms, ms, nil, nil, events.FormatJSON, provider, services.LeaderboardConfig{})
+	eventHandler := handlers.NewVideoEventHandler(rankingService, time.Minute)
+	videoHandler := NewVideoHandler(rankingService, validator.New(), eventHandler)
+	router := gin.New()
+	router.POST("/videos/:id/like", videoHandler.HandleLike)
+
+	ranked := &models.Video{ID: uuid.New(), Title: "ranked"}
+	liked := &models.Video{ID: uuid.New(), Title: "liked"}
+	for _, video := range []*models.Video{ranked, liked} {
+		if err := rankingService.CreateVideo(context.Background(), video); err != nil {
+			t.Fatalf("CreateVideo: %v", err)
+		}
+	}
+	if _, err := rankingService.ApplyVideoDelta(context.Background(), models.VideoDelta{VideoID: ranked.ID, Likes: 10}, time.Now()); err != nil {
+		t.Fatalf("ApplyVideoDelta: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		videoID        uuid.UUID
+		idempotencyKey string
+		wantStatus     int
+		wantRank       int64
+	}{
+		{"first like", liked.ID, "like-1", http.StatusOK, 1},
+		{"repeated key counts once", liked.ID, "like-1", http.StatusOK, 1},
+		{"like without a key", ranked.ID, "", http.StatusOK, 0},
+		{"unknown video", uuid.New(), "", http.StatusNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/videos/"+tt.videoID.String()+"/like?userID=user-1", nil)
+			if tt.idempotencyKey != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.idempotencyKey)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response EventResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			video, err := rankingService.GetVideo(context.Background(), tt.videoID)
+			if err != nil {
+				t.Fatalf("GetVideo: %v", err)
+			}
+			if response.Score == nil || *response.Score != video.Score {
+				t.Errorf("score = %v, want %v", response.Score, video.Score)
+			}
+			if response.Rank == nil || *response.Rank != tt.wantRank {
+				t.Errorf("rank = %v, want %d", response.Rank, tt.wantRank)
+			}
+		})
+	}
+
+	video, err := rankingService.GetVideo(context.Background(), liked.ID)
+	if err != nil {
+		t.Fatalf("GetVideo: %v", err)
+	}
+	if video.Likes != 1 {
+		t.Errorf("likes = %d, want 1", video.Likes)
+	}
+}
+
+func TestGetTopVideosPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider, err := scoring.NewProvider("")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ms := store.NewMemoryStore(time.Hour)
+	rankingService := services.NewRankingService(ms, ms, nil, nil, events.FormatJSON, provider, services.LeaderboardConfig{})
+	videoHandler := NewVideoHandler(rankingService, validator.New(), handlers.NewVideoEventHandler(rankingService, time.Minute))
+	router := gin.New()
+	router.GET("/videos/top", videoHandler.GetTopVideos)
+	router.GET("/users/:userID/videos/top", videoHandler.GetTopVideosPerUser)
+
+	for i := 0; i < 3; i++ {
+		video := &models.Video{ID: uuid.New(), Title: "video"}
+		if err := rankingService.CreateVideo(context.Background(), video); err != nil {
+			t.Fatalf("CreateVideo: %v", err)
+		}
+		if _, err := rankingService.ApplyVideoDelta(context.Background(), models.VideoDelta{VideoID: video.ID, Likes: i + 1}, time.Now()); err != nil {
+			t.Fatalf("ApplyVideoDelta: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantVideos int
+	}{
+		{"defaults", "", http.StatusOK, 3},
+		{"page", "?start=1&count=1", http.StatusOK, 1},
+		{"largest page", "?count=100", http.StatusOK, 3},
+		{"negative start", "?start=-1", http.StatusBadRequest, 0},
+		{"zero count", "?count=0", http.StatusBadRequest, 0},
+		{"count too large", "?count=101", http.StatusBadRequest, 0},
+		{"malformed count", "?count=ten", http.StatusBadRequest, 0},
+	}
+	for _, path := range []string{"/videos/top", "/users/user-1/videos/top"} {
+		for _, tt := range tests {
+			t.Run(path+" "+tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+tt.query, nil))
+
+				if rec.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+				var videos []models.Video
+				if err := json.Unmarshal(rec.Body.Bytes(), &videos); err != nil {
+					t.Fatalf("decoding response: %v", err)
+				}
+				if len(videos) != tt.wantVideos {
+					t.Errorf("got %d videos, want %d", len(videos), tt.wantVideos)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Manager shuts down the service's components in a fixed order under one deadline.
+type Manager struct {
+	mu    sync.Mutex
+	hooks []hook
+}
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// OnShutdown registers stop to run during Shutdown. Hooks run one at a time in the
+// order they were registered, so components that feed others should be registered
+// first (for example the HTTP server before the stores it writes to).
+func (m *Manager) OnShutdown(name string, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// OnShutdownClose registers a Close method that does not accept a context. Shutdown
+// stops waiting for it once ctx is done.
+func (m *Manager) OnShutdownClose(name string, close func() error) {
+	m.OnShutdown(name, func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() { done <- close() }()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// Shutdown runs every hook, sharing ctx's deadline between them. A failing hook does
+// not stop later ones from running; all failures are returned together.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, h := range hooks {
+		started := time.Now()
+		if err := h.stop(ctx); err != nil {
+			log.Printf("Error stopping %s: %v", h.name, err)
+			errs = append(errs, fmt.Errorf("error stopping %s: %w", h.name, err))
+			continue
+		}
+		log.Printf("Stopped %s in %v", h.name, time.Since(started).Round(time.Millisecond))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS videos (
+    id          UUID PRIMARY KEY,
+    title       VARCHAR(255)     NOT NULL,
+    data        TEXT             NOT NULL,
+    score       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    views       BIGINT           NOT NULL DEFAULT 0,
+    likes       BIGINT           NOT NULL DEFAULT 0,
+    comments    BIGINT           NOT NULL DEFAULT 0,
+    shares      BIGINT           NOT NULL DEFAULT 0,
+    watch_time  BIGINT           NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_videos_score ON videos (score DESC);
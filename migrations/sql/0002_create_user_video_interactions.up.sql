@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_video_interactions (
+    user_id     VARCHAR(255) NOT NULL,
+    video_id    UUID         NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+    last_viewed TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    views       BIGINT       NOT NULL DEFAULT 0,
+    likes       BIGINT       NOT NULL DEFAULT 0,
+    comments    BIGINT       NOT NULL DEFAULT 0,
+    shares      BIGINT       NOT NULL DEFAULT 0,
+    watch_time  BIGINT       NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, video_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_video_interactions_video_id ON user_video_interactions (video_id);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id    VARCHAR(255) PRIMARY KEY,
+    categories JSONB        NOT NULL DEFAULT '[]'::jsonb,
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS scoring_version VARCHAR(64) NOT NULL DEFAULT '';
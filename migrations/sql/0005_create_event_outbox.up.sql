@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id          BIGSERIAL    PRIMARY KEY,
+    topic       VARCHAR(255) NOT NULL,
+    message_key VARCHAR(255) NOT NULL DEFAULT '',
+    payload     BYTEA        NOT NULL,
+    attempts    INT          NOT NULL DEFAULT 0,
+    last_error  TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
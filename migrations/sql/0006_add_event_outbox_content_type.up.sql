@@ -0,0 +1 @@
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS content_type VARCHAR(64) NOT NULL DEFAULT '';
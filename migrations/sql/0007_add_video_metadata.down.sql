@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS video_tags;
+DROP TABLE IF EXISTS video_categories;
+
+DROP INDEX IF EXISTS idx_videos_creator_id;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS duration_seconds;
+ALTER TABLE videos DROP COLUMN IF EXISTS language;
+ALTER TABLE videos DROP COLUMN IF EXISTS creator_id;
@@ -0,0 +1,23 @@
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS creator_id       VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS language         VARCHAR(35)  NOT NULL DEFAULT '';
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS duration_seconds INT          NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_videos_creator_id ON videos (creator_id);
+
+CREATE TABLE IF NOT EXISTS video_categories (
+    video_id UUID         NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+    category VARCHAR(255) NOT NULL,
+    position INT          NOT NULL DEFAULT 0,
+    PRIMARY KEY (video_id, category)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_categories_category ON video_categories (category);
+
+CREATE TABLE IF NOT EXISTS video_tags (
+    video_id UUID         NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+    tag      VARCHAR(255) NOT NULL,
+    position INT          NOT NULL DEFAULT 0,
+    PRIMARY KEY (video_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_tags_tag ON video_tags (tag);
@@ -1,10 +1,73 @@
 package models
 
 import (
+	"fmt"
 	"github.com/google/uuid"
+	"strings"
 	"time"
 )
 
+// RankingMode selects which leaderboard top videos are read from.
+type RankingMode string
+
+const (
+	// RankingModeAllTime ranks videos by their accumulated score.
+	RankingModeAllTime RankingMode = "all_time"
+	// RankingModeHot ranks videos by a score that decays with a configurable half-life.
+	RankingModeHot RankingMode = "hot"
+)
+
+func ParseRankingMode(mode string) (RankingMode, error) {
+	switch RankingMode(mode) {
+	case "", RankingModeAllTime:
+		return RankingModeAllTime, nil
+	case RankingModeHot:
+		return RankingModeHot, nil
+	default:
+		return "", fmt.Errorf("unknown ranking mode %q: expected %s or %s", mode, RankingModeAllTime, RankingModeHot)
+	}
+}
+
+// RankingWindow selects a rolling time window for windowed leaderboards.
+type RankingWindow string
+
+const (
+	RankingWindowHour RankingWindow = "1h"
+	RankingWindowDay  RankingWindow = "24h"
+	RankingWindowWeek RankingWindow = "7d"
+)
+
+// Duration returns the length of the window.
+func (w RankingWindow) Duration() time.Duration {
+	switch w {
+	case RankingWindowHour:
+		return time.Hour
+	case RankingWindowDay:
+		return 24 * time.Hour
+	case RankingWindowWeek:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+func ParseRankingWindow(window string) (RankingWindow, error) {
+	switch RankingWindow(window) {
+	case RankingWindowHour, RankingWindowDay, RankingWindowWeek:
+		return RankingWindow(window), nil
+	default:
+		return "", fmt.Errorf("unknown ranking window %q: expected %s, %s or %s", window, RankingWindowHour, RankingWindowDay, RankingWindowWeek)
+	}
+}
+
+// LabelKind selects which of a video's labels a per-label leaderboard is keyed by.
+type LabelKind string
+
+const (
+	LabelKindCategory LabelKind = "category"
+	LabelKindTag      LabelKind = "tag"
+)
+
 const (
 	ViewAction      = "view"
 	LikeAction      = "like"
@@ -23,41 +86,292 @@ type Video struct {
 	Comments  int       `json:"comments"`
 	Shares    int       `json:"shares"`
 	WatchTime int       `json:"watchTime"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	// ScoringVersion is the scoring config version that computed Score.
+	ScoringVersion string `json:"scoringVersion,omitempty"`
+	// CreatorID identifies the user who published the video.
+	CreatorID string `json:"creatorId,omitempty"`
+	// Language is a BCP 47 language tag such as "en" or "vi".
+	Language        string `json:"language,omitempty"`
+	DurationSeconds int    `json:"durationSeconds,omitempty"`
+	// Categories and Tags are normalized with NormalizeLabels.
+	Categories []string  `json:"categories"`
+	Tags       []string  `json:"tags"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Labels returns the video's labels of the given kind.
+func (v *Video) Labels(kind LabelKind) []string {
+	switch kind {
+	case LabelKindCategory:
+		return v.Categories
+	case LabelKindTag:
+		return v.Tags
+	default:
+		return nil
+	}
+}
+
+// VideoDelta is an increment applied atomically to a video's engagement counters. The
+// video's score is recomputed from the updated counters in the same write.
+type VideoDelta struct {
+	VideoID   uuid.UUID
+	Views     int
+	Likes     int
+	Comments  int
+	Shares    int
+	WatchTime int
+	// ScoringVersion identifies the scoring config used to recompute the score.
+	ScoringVersion string
+	// Audiences breaks the counters down by the audiences the events came from. Events
+	// without a region or locale only count towards the global leaderboards.
+	Audiences map[Audience]VideoDelta
+}
+
+// Add adds other's engagement counters to d.
+func (d *VideoDelta) Add(other VideoDelta) {
+	d.Views += other.Views
+	d.Likes += other.Likes
+	d.Comments += other.Comments
+	d.Shares += other.Shares
+	d.WatchTime += other.WatchTime
 }
 
 type CreateVideoRequest struct {
-	Title string `json:"title" binding:"required,min=1,max=255"`
-	Data  string `json:"data" binding:"required"`
+	Title           string   `json:"title" binding:"required,min=1,max=255"`
+	Data            string   `json:"data" binding:"required"`
+	CreatorID       string   `json:"creatorId,omitempty" binding:"omitempty,max=255"`
+	Language        string   `json:"language,omitempty" binding:"omitempty,max=35"`
+	DurationSeconds int      `json:"durationSeconds,omitempty" binding:"omitempty,min=0"`
+	Categories      []string `json:"categories,omitempty" binding:"omitempty,max=20,dive,min=1,max=255"`
+	Tags            []string `json:"tags,omitempty" binding:"omitempty,max=50,dive,min=1,max=255"`
 }
 
+// UpdateVideoRequest carries a partial metadata update; omitted fields are left unchanged.
+// Categories and Tags replace the video's current lists when present.
 type UpdateVideoRequest struct {
-	Title string `json:"title" binding:"required,min=1,max=255"`
-	Data  string `json:"data" binding:"required"`
+	Title           *string   `json:"title,omitempty" binding:"omitempty,min=1,max=255"`
+	Data            *string   `json:"data,omitempty" binding:"omitempty,min=1"`
+	Language        *string   `json:"language,omitempty" binding:"omitempty,max=35"`
+	DurationSeconds *int      `json:"durationSeconds,omitempty" binding:"omitempty,min=0"`
+	Categories      *[]string `json:"categories,omitempty" binding:"omitempty,max=20,dive,min=1,max=255"`
+	Tags            *[]string `json:"tags,omitempty" binding:"omitempty,max=50,dive,min=1,max=255"`
+}
+
+// VideoMetadataUpdate lists the metadata fields to change on a video. Nil fields are
+// left untouched, and engagement counters and scores are never modified.
+type VideoMetadataUpdate struct {
+	Title           *string
+	Data            *string
+	Language        *string
+	DurationSeconds *int
+	Categories      *[]string
+	Tags            *[]string
 }
 
+func (u VideoMetadataUpdate) IsEmpty() bool {
+	return u.Title == nil && u.Data == nil && u.Language == nil && u.DurationSeconds == nil && u.Categories == nil && u.Tags == nil
+}
+
+// NormalizeLabels trims and lower-cases category or tag names and drops empty and
+// repeated ones, keeping the first occurrence's position.
+func NormalizeLabels(labels []string) []string {
+	normalized := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+	return normalized
+}
+
+// VideoEventSchemaVersion is the schema version of the VideoEvents this service
+// publishes. Version 1 events carried an untyped "value" instead of typed payloads.
+const VideoEventSchemaVersion = 2
+
 type VideoEvent struct {
-	VideoID uuid.UUID `json:"video_id"`
-	Action  string    `json:"action"`
-	UserID  string    `json:"user_id"`
-	Value   interface{} `json:"value,omitempty"`
+	// SchemaVersion is the schema the event was published with. It is set when the
+	// event is published; events decoded from older versions keep their version.
+	SchemaVersion int `json:"schema_version"`
+	// EventID uniquely identifies the event so redelivered copies can be dropped.
+	// It is supplied by the client or assigned when the event is published.
+	EventID   string    `json:"event_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	VideoID   uuid.UUID `json:"video_id"`
+	Action    string    `json:"action"`
+	UserID    string    `json:"user_id"`
+	// WatchTime is the payload of watch_time events and is nil for other actions.
+	WatchTime *WatchTimePayload `json:"watch_time,omitempty"`
+	// Region is the ISO 3166-1 alpha-2 country the event came from, such as "VN".
+	Region string `json:"region,omitempty"`
+	// Locale is the viewer's BCP 47 locale, such as "vi-VN".
+	Locale string `json:"locale,omitempty"`
+}
+
+// Audiences returns the audience-scoped leaderboards the event counts towards: its
+// region, taken from the locale if not set, and the locale's language.
+func (e *VideoEvent) Audiences() []Audience {
+	var audiences []Audience
+	language, localeRegion := splitLocale(e.Locale)
+	region := strings.ToUpper(e.Region)
+	if region == "" {
+		region = localeRegion
+	}
+	if isRegionCode(region) {
+		audiences = append(audiences, Audience{Kind: AudienceKindRegion, Name: region})
+	}
+	if language != "" {
+		audiences = append(audiences, Audience{Kind: AudienceKindLanguage, Name: language})
+	}
+	return audiences
+}
+
+// AudienceKind selects what audience-scoped leaderboards are keyed by.
+type AudienceKind string
+
+const (
+	AudienceKindRegion   AudienceKind = "region"
+	AudienceKindLanguage AudienceKind = "language"
+)
+
+// Audience identifies an audience-scoped leaderboard, such as the one for region VN.
+type Audience struct {
+	Kind AudienceKind
+	Name string
+}
+
+func (a Audience) String() string {
+	return string(a.Kind) + ":" + a.Name
+}
+
+// ParseRegion validates an ISO 3166-1 alpha-2 region code and upper-cases it.
+func ParseRegion(region string) (string, error) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if !isRegionCode(region) {
+		return "", fmt.Errorf("invalid region %q: expected a two-letter ISO 3166-1 code such as VN", region)
+	}
+	return region, nil
+}
+
+// ParseLanguage validates an ISO 639 language code and lower-cases it.
+func ParseLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if !isLanguageCode(language) {
+		return "", fmt.Errorf("invalid language %q: expected a two- or three-letter ISO 639 code such as vi", language)
+	}
+	return language, nil
+}
+
+// ParseLocale validates a BCP 47 locale such as "vi-VN" or "vi_VN" and returns it in
+// canonical form. Only the language and region subtags are kept.
+func ParseLocale(locale string) (string, error) {
+	language, region := splitLocale(locale)
+	if language == "" {
+		return "", fmt.Errorf("invalid locale %q: expected a BCP 47 tag such as vi-VN", locale)
+	}
+	if region == "" {
+		return language, nil
+	}
+	return language + "-" + region, nil
+}
+
+// splitLocale returns the language and region subtags of locale, or empty strings for
+// the parts that are missing or malformed.
+func splitLocale(locale string) (language, region string) {
+	subtags := strings.FieldsFunc(strings.TrimSpace(locale), func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 || !isLanguageCode(strings.ToLower(subtags[0])) {
+		return "", ""
+	}
+	language = strings.ToLower(subtags[0])
+	// A script subtag such as "Hant" may come before the region.
+	for _, subtag := range subtags[1:] {
+		if subtag = strings.ToUpper(subtag); isRegionCode(subtag) {
+			return language, subtag
+		}
+	}
+	return language, ""
+}
+
+func isRegionCode(code string) bool {
+	return len(code) == 2 && isLetters(code, 'A', 'Z')
+}
+
+func isLanguageCode(code string) bool {
+	return (len(code) == 2 || len(code) == 3) && isLetters(code, 'a', 'z')
+}
+
+func isLetters(s string, first, last byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < first || s[i] > last {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchTimePayload is the payload of a watch_time event.
+type WatchTimePayload struct {
+	Seconds int `json:"seconds"`
+}
+
+// OutboxMessage is a message waiting in the outbox to be published to Kafka.
+type OutboxMessage struct {
+	ID      int64
+	Topic   string
+	Key     string
+	Payload []byte
+	// ContentType is published as the message's content-type header when set.
+	ContentType string
+	Attempts    int
+	CreatedAt   time.Time
 }
 
 type UserVideoInteraction struct {
-	UserID    string    `json:"userId"`
-	VideoID   uuid.UUID `json:"videoId"`
+	UserID     string    `json:"userId"`
+	VideoID    uuid.UUID `json:"videoId"`
 	LastViewed time.Time `json:"lastViewed"`
-	Views     int       `json:"views"`
-	Likes     int       `json:"likes"`
-	Comments  int       `json:"comments"`
-	Shares    int       `json:"shares"`
-	WatchTime int       `json:"watchTime"`
+	Views      int       `json:"views"`
+	Likes      int       `json:"likes"`
+	Comments   int       `json:"comments"`
+	Shares     int       `json:"shares"`
+	WatchTime  int       `json:"watchTime"`
+}
+
+// VideoRank is a video's position in the all-time leaderboard.
+type VideoRank struct {
+	VideoID uuid.UUID `json:"videoId"`
+	// Rank is 0-based; the top video has rank 0.
+	Rank  int64   `json:"rank"`
+	Score float64 `json:"score"`
+	// Total is the number of ranked videos.
+	Total int64 `json:"total"`
+	// Percentile is the percentage of ranked videos at or below this one, so the top
+	// video is at 100.
+	Percentile float64 `json:"percentile"`
+}
+
+// VideoNeighbors is a video's rank together with the videos ranked just above and
+// below it, both ordered from highest to lowest score.
+type VideoNeighbors struct {
+	Rank  VideoRank `json:"rank"`
+	Above []Video   `json:"above"`
+	Below []Video   `json:"below"`
+}
+
+// CreatorScore is a creator's entry in the creator leaderboard. Score aggregates the
+// scores of the creator's videos.
+type CreatorScore struct {
+	CreatorID string  `json:"creatorId"`
+	Score     float64 `json:"score"`
 }
 
 type UserPreference struct {
-	UserID     string   `json:"userId"`
-	Categories []string `json:"categories"`
+	UserID     string    `json:"userId"`
+	Categories []string  `json:"categories"`
 	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
@@ -68,4 +382,4 @@ type Category struct {
 
 type UpdateUserPreferencesRequest struct {
 	Categories []string `json:"categories" binding:"required,dive,min=1,max=255"`
-}
\ No newline at end of file
+}
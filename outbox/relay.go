@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"realtime-ranking/eventbus"
+	"realtime-ranking/events"
+	"realtime-ranking/models"
+	"realtime-ranking/store"
+	"sync"
+	"time"
+)
+
+// Relay tuning. The relay polls every pollInterval while the outbox is empty and
+// immediately while it keeps returning full batches. After a failed publish it backs
+// off exponentially up to maxBackoff.
+const (
+	batchSize    = 100
+	pollInterval = 500 * time.Millisecond
+	maxBackoff   = 30 * time.Second
+)
+
+// Relay publishes messages from the outbox to the event bus and removes them once the
+// bus has accepted them. A message may be published more than once if the relay stops
+// between the write and the removal; consumers drop the duplicate by event ID.
+type Relay struct {
+	outbox    store.EventOutbox
+	publisher eventbus.Publisher
+
+	stopping  chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewRelay returns a relay that publishes with publisher, which must be synchronous so
+// that a successful publish means the bus has the message.
+func NewRelay(outbox store.EventOutbox, publisher eventbus.Publisher) *Relay {
+	return &Relay{outbox: outbox, publisher: publisher, stopping: make(chan struct{}), done: make(chan struct{})}
+}
+
+// Run relays messages until Stop is called.
+func (r *Relay) Run() {
+	defer close(r.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backoff := pollInterval
+	for {
+		published, err := r.outbox.PublishOutboxMessages(ctx, batchSize, r.publish)
+		wait := pollInterval
+		switch {
+		case err != nil:
+			log.Printf("Error relaying outbox messages, retrying in %v: %v", backoff, err)
+			wait = backoff
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		case published == batchSize:
+			backoff = pollInterval
+			wait = 0
+		default:
+			backoff = pollInterval
+		}
+
+		select {
+		case <-r.stopping:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Stop waits for the batch in progress to finish, stops the relay and closes its
+// publisher. Messages left in the outbox are relayed after the next start. It is safe
+// to call more than once, for example again after a call that timed out.
+func (r *Relay) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stopping) })
+	select {
+	case <-r.done:
+		r.closeOnce.Do(func() { r.closeErr = r.publisher.Close() })
+		return r.closeErr
+	case <-ctx.Done():
+		return fmt.Errorf("outbox relay did not stop in time: %w", ctx.Err())
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, messages []models.OutboxMessage) error {
+	busMessages := make([]eventbus.Message, len(messages))
+	for i, message := range messages {
+		busMessages[i] = eventbus.Message{
+			Topic: message.Topic,
+			Key:   []byte(message.Key),
+			Value: message.Payload,
+		}
+		if message.ContentType != "" {
+			busMessages[i].Headers = []eventbus.Header{{Key: events.ContentTypeHeader, Value: []byte(message.ContentType)}}
+		}
+	}
+	if err := r.publisher.Publish(ctx, busMessages...); err != nil {
+		return fmt.Errorf("error publishing outbox messages: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"realtime-ranking/eventbus"
+	"realtime-ranking/store"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingPublisher discards messages and counts how often it is closed.
+type countingPublisher struct {
+	closed atomic.Int32
+}
+
+func (cp *countingPublisher) Publish(ctx context.Context, messages ...eventbus.Message) error {
+	return nil
+}
+
+func (cp *countingPublisher) Close() error {
+	cp.closed.Add(1)
+	return nil
+}
+
+func TestRelayStopTwice(t *testing.T) {
+	publisher := &countingPublisher{}
+	relay := NewRelay(store.NewMemoryStore(time.Hour), publisher)
+	go relay.Run()
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := relay.Stop(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("Stop #%d: %v", i+1, err)
+		}
+	}
+	if closed := publisher.closed.Load(); closed != 1 {
+		t.Errorf("publisher closed %d times, want 1", closed)
+	}
+}
@@ -0,0 +1,190 @@
+package scoring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"realtime-ranking/models"
+	"reflect"
+	"sync"
+)
+
+// EventWeights is the score added to a video for each kind of engagement.
+type EventWeights struct {
+	View        float64 `json:"view"`
+	Like        float64 `json:"like"`
+	Comment     float64 `json:"comment"`
+	Share       float64 `json:"share"`
+	WatchSecond float64 `json:"watchSecond"`
+}
+
+// PersonalizationWeights boost a video for a user based on that user's own history
+// and category preferences.
+type PersonalizationWeights struct {
+	View          float64 `json:"view"`
+	Like          float64 `json:"like"`
+	Comment       float64 `json:"comment"`
+	Share         float64 `json:"share"`
+	WatchSecond   float64 `json:"watchSecond"`
+	CategoryMatch float64 `json:"categoryMatch"`
+	// RecencyHours controls how quickly interaction boosts fade: a boost is divided by
+	// 1 + hoursSinceLastView/RecencyHours.
+	RecencyHours float64 `json:"recencyHours"`
+}
+
+// Config holds every tunable weight used when scoring and personalizing videos.
+// Version identifies the config so scores can be traced back to the weights that
+// produced them; when omitted it is derived from the config contents.
+type Config struct {
+	Version         string                 `json:"version"`
+	Events          EventWeights           `json:"events"`
+	Personalization PersonalizationWeights `json:"personalization"`
+	// Scorers selects the ranking formula for each leaderboard by scorer name.
+	Scorers map[models.RankingMode]string `json:"scorers"`
+}
+
+// DefaultConfig returns the weights used when no config file is provided.
+func DefaultConfig() Config {
+	return Config{
+		Version: "default",
+		Events: EventWeights{
+			View:        1,
+			Like:        5,
+			Comment:     3,
+			Share:       4,
+			WatchSecond: 0.05,
+		},
+		Personalization: PersonalizationWeights{
+			View:          0.1,
+			Like:          0.5,
+			Comment:       0.8,
+			Share:         1.2,
+			WatchSecond:   0.05,
+			CategoryMatch: 3.0,
+			RecencyHours:  24,
+		},
+		Scorers: map[models.RankingMode]string{
+			models.RankingModeAllTime: LinearScorerName,
+			models.RankingModeHot:     LinearScorerName,
+		},
+	}
+}
+
+// ScorerFor returns the scorer configured for a leaderboard, defaulting to Linear.
+func (c Config) ScorerFor(mode models.RankingMode) Scorer {
+	if scorer, err := ScorerByName(c.Scorers[mode]); err == nil {
+		return scorer
+	}
+	return Linear{}
+}
+
+func (c Config) Validate() error {
+	weights := map[string]float64{
+		"events.view":                   c.Events.View,
+		"events.like":                   c.Events.Like,
+		"events.comment":                c.Events.Comment,
+		"events.share":                  c.Events.Share,
+		"events.watchSecond":            c.Events.WatchSecond,
+		"personalization.view":          c.Personalization.View,
+		"personalization.like":          c.Personalization.Like,
+		"personalization.comment":       c.Personalization.Comment,
+		"personalization.share":         c.Personalization.Share,
+		"personalization.watchSecond":   c.Personalization.WatchSecond,
+		"personalization.categoryMatch": c.Personalization.CategoryMatch,
+		"personalization.recencyHours":  c.Personalization.RecencyHours,
+	}
+	for name, weight := range weights {
+		if weight < 0 {
+			return fmt.Errorf("scoring weight %s must not be negative, got %v", name, weight)
+		}
+	}
+	if c.Personalization.RecencyHours == 0 {
+		return fmt.Errorf("scoring weight personalization.recencyHours must be positive")
+	}
+	for mode, name := range c.Scorers {
+		if _, err := models.ParseRankingMode(string(mode)); err != nil || mode == "" {
+			return fmt.Errorf("scorers: unknown leaderboard %q", mode)
+		}
+		scorer, err := ScorerByName(name)
+		if err != nil {
+			return fmt.Errorf("scorers.%s: %w", mode, err)
+		}
+		// The hot leaderboard decays scores itself, so it needs deltas; an absolute
+		// score that already accounts for age would decay twice.
+		if mode == models.RankingModeHot && scorer.Kind() != Incremental {
+			return fmt.Errorf("scorers.%s: scorer %q is not incremental", mode, name)
+		}
+	}
+	return nil
+}
+
+// LoadConfigFile reads a JSON config. Fields missing from the file keep their default values.
+func LoadConfigFile(path string) (Config, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("error reading scoring config %s: %w", path, err)
+	}
+
+	config := DefaultConfig()
+	config.Version = ""
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return Config{}, fmt.Errorf("error parsing scoring config %s: %w", path, err)
+	}
+	if config.Version == "" {
+		sum := sha256.Sum256(contents)
+		config.Version = "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid scoring config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Provider holds the active scoring config and can reload it while the service is running.
+type Provider struct {
+	mu      sync.RWMutex
+	current Config
+	path    string
+}
+
+// NewProvider loads the config at path, or uses DefaultConfig when path is empty.
+func NewProvider(path string) (*Provider, error) {
+	provider := &Provider{path: path, current: DefaultConfig()}
+	if path == "" {
+		return provider, nil
+	}
+	if _, err := provider.Reload(); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (p *Provider) Current() Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current
+}
+
+// Reload re-reads the config file. The previous config stays active if the new one is
+// invalid, or if it changes the config without changing its version: stored scores are
+// matched to configs by version, so each version must always mean the same weights.
+func (p *Provider) Reload() (Config, error) {
+	if p.path == "" {
+		return p.Current(), nil
+	}
+
+	config, err := LoadConfigFile(p.path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if config.Version == p.current.Version && !reflect.DeepEqual(config, p.current) {
+		return Config{}, fmt.Errorf("scoring config %s changed without a new version; change its version field", p.path)
+	}
+	p.current = config
+	return config, nil
+}
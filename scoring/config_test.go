@@ -0,0 +1,109 @@
+package scoring
+
+import (
+	"os"
+	"path/filepath"
+	"realtime-ranking/models"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{"default", func(c *Config) {}, false},
+		{"zero weight", func(c *Config) { c.Events.Comment = 0 }, false},
+		{"negative event weight", func(c *Config) { c.Events.Like = -1 }, true},
+		{"negative personalization weight", func(c *Config) { c.Personalization.CategoryMatch = -0.5 }, true},
+		{"zero recency", func(c *Config) { c.Personalization.RecencyHours = 0 }, true},
+		{"absolute all-time scorer", func(c *Config) { c.Scorers[models.RankingModeAllTime] = WilsonScorerName }, false},
+		{"absolute hot scorer", func(c *Config) { c.Scorers[models.RankingModeHot] = RedditHotScorerName }, true},
+		{"incremental hot scorer", func(c *Config) { c.Scorers[models.RankingModeHot] = LinearScorerName }, false},
+		{"unknown scorer", func(c *Config) { c.Scorers[models.RankingModeAllTime] = "pagerank" }, true},
+		{"unknown leaderboard", func(c *Config) { c.Scorers["weekly"] = LinearScorerName }, true},
+		{"empty leaderboard", func(c *Config) { c.Scorers[""] = LinearScorerName }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	config, err := LoadConfigFile(write("partial.json", `{"version": "v2", "events": {"like": 7}}`))
+	if err != nil {
+		t.Fatalf("LoadConfigFile: %v", err)
+	}
+	if config.Version != "v2" || config.Events.Like != 7 || config.Events.View != DefaultConfig().Events.View {
+		t.Errorf("LoadConfigFile = %+v, want version v2, like 7 and the default view weight", config)
+	}
+
+	unversioned, err := LoadConfigFile(write("unversioned.json", `{"events": {"like": 7}}`))
+	if err != nil {
+		t.Fatalf("LoadConfigFile: %v", err)
+	}
+	if len(unversioned.Version) != len("sha256:")+12 || unversioned.Version[:7] != "sha256:" {
+		t.Errorf("derived version = %q, want sha256:<12 hex digits>", unversioned.Version)
+	}
+
+	if _, err := LoadConfigFile(write("invalid.json", `{"events": {"like": -1}}`)); err == nil {
+		t.Error("LoadConfigFile accepted a negative weight")
+	}
+	if _, err := LoadConfigFile(write("malformed.json", `{"events": `)); err == nil {
+		t.Error("LoadConfigFile accepted malformed JSON")
+	}
+}
+
+func TestProviderReloadRequiresNewVersionForChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scoring.json")
+	write := func(contents string) {
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"version": "v1", "events": {"like": 7}}`)
+	provider, err := NewProvider(path)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contents    string
+		wantErr     bool
+		wantVersion string
+		wantLike    float64
+	}{
+		{"unchanged", `{"version": "v1", "events": {"like": 7}}`, false, "v1", 7},
+		{"changed under the same version", `{"version": "v1", "events": {"like": 9}}`, true, "v1", 7},
+		{"changed with a new version", `{"version": "v2", "events": {"like": 9}}`, false, "v2", 9},
+		{"back to an earlier version", `{"version": "v1", "events": {"like": 7}}`, false, "v1", 7},
+	}
+	for _, tt := range tests {
+		write(tt.contents)
+		if _, err := provider.Reload(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Reload() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if current := provider.Current(); current.Version != tt.wantVersion || current.Events.Like != tt.wantLike {
+			t.Errorf("%s: active config is version %s with like weight %v, want %s and %v",
+				tt.name, current.Version, current.Events.Like, tt.wantVersion, tt.wantLike)
+		}
+	}
+}
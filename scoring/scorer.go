@@ -0,0 +1,151 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"realtime-ranking/models"
+	"sort"
+	"time"
+)
+
+// Kind tells the caller how to apply the value returned by a Scorer.
+type Kind int
+
+const (
+	// Incremental scorers return a delta that is added to the leaderboard score.
+	Incremental Kind = iota
+	// Absolute scorers return the video's new leaderboard score.
+	Absolute
+)
+
+// Input is what a Scorer sees when a video's engagement changes.
+type Input struct {
+	// Video holds the counters after Delta has been applied. Incremental scorers may
+	// run before the counters are written and must only use Delta.
+	Video   models.Video
+	Delta   models.VideoDelta
+	Weights EventWeights
+}
+
+// Scorer is a ranking formula. Leaderboards pick a scorer by name in the scoring config.
+type Scorer interface {
+	Name() string
+	Kind() Kind
+	Score(in Input) float64
+}
+
+const (
+	LinearScorerName    = "linear"
+	RedditHotScorerName = "reddit_hot"
+	WilsonScorerName    = "wilson"
+)
+
+var scorers = map[string]Scorer{
+	LinearScorerName:    Linear{},
+	RedditHotScorerName: RedditHot{},
+	WilsonScorerName:    Wilson{Z: 1.96},
+}
+
+// ScorerByName returns the built-in scorer with the given name.
+func ScorerByName(name string) (Scorer, error) {
+	scorer, ok := scorers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scorer %q: expected one of %v", name, ScorerNames())
+	}
+	return scorer, nil
+}
+
+func ScorerNames() []string {
+	names := make([]string, 0, len(scorers))
+	for name := range scorers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VideoScore returns the video's score from all of its engagement so far. Incremental
+// scorers are applied to the video's counters as a single delta, which gives the same
+// total as adding up the score of every event.
+func VideoScore(scorer Scorer, video models.Video, weights EventWeights) float64 {
+	in := Input{Video: video, Weights: weights}
+	if scorer.Kind() == Incremental {
+		in.Delta = models.VideoDelta{
+			VideoID:   video.ID,
+			Views:     video.Views,
+			Likes:     video.Likes,
+			Comments:  video.Comments,
+			Shares:    video.Shares,
+			WatchTime: video.WatchTime,
+		}
+	}
+	return scorer.Score(in)
+}
+
+// weightedEngagement is the linear-weighted sum of a set of counters.
+func weightedEngagement(weights EventWeights, views, likes, comments, shares, watchTime int) float64 {
+	return float64(views)*weights.View +
+		float64(likes)*weights.Like +
+		float64(comments)*weights.Comment +
+		float64(shares)*weights.Share +
+		float64(watchTime)*weights.WatchSecond
+}
+
+// Linear adds the weighted sum of the new engagement to the score.
+type Linear struct{}
+
+func (Linear) Name() string { return LinearScorerName }
+func (Linear) Kind() Kind   { return Incremental }
+
+func (Linear) Score(in Input) float64 {
+	d := in.Delta
+	return weightedEngagement(in.Weights, d.Views, d.Likes, d.Comments, d.Shares, d.WatchTime)
+}
+
+// RedditHot is Reddit's "hot" formula: the order of magnitude of the weighted
+// engagement plus a bonus that grows linearly with the video's creation time, so a
+// video needs ten times the engagement to outrank one posted 12.5 hours later.
+type RedditHot struct{}
+
+// redditEpoch is the reference point used by the original formula.
+var redditEpoch = time.Unix(1134028003, 0)
+
+func (RedditHot) Name() string { return RedditHotScorerName }
+func (RedditHot) Kind() Kind   { return Absolute }
+
+func (RedditHot) Score(in Input) float64 {
+	v := in.Video
+	engagement := weightedEngagement(in.Weights, v.Views, v.Likes, v.Comments, v.Shares, v.WatchTime)
+	order := math.Log10(math.Max(math.Abs(engagement), 1))
+	sign := 0.0
+	if engagement > 0 {
+		sign = 1
+	} else if engagement < 0 {
+		sign = -1
+	}
+	seconds := v.CreatedAt.Sub(redditEpoch).Seconds()
+	return sign*order + seconds/45000
+}
+
+// Wilson ranks by the lower bound of the Wilson score interval for the share of views
+// that turned into a like, which favours videos with a high like rate backed by
+// enough views to trust it.
+type Wilson struct {
+	// Z is the normal quantile for the desired confidence, e.g. 1.96 for 95%.
+	Z float64
+}
+
+func (Wilson) Name() string { return WilsonScorerName }
+func (Wilson) Kind() Kind   { return Absolute }
+
+func (w Wilson) Score(in Input) float64 {
+	positive := float64(in.Video.Likes)
+	total := math.Max(float64(in.Video.Views), positive)
+	if total == 0 {
+		return 0
+	}
+
+	p := positive / total
+	z2 := w.Z * w.Z
+	return (p + z2/(2*total) - w.Z*math.Sqrt((p*(1-p)+z2/(4*total))/total)) / (1 + z2/total)
+}
@@ -0,0 +1,103 @@
+package scoring
+
+import (
+	"math"
+	"realtime-ranking/models"
+	"testing"
+	"time"
+)
+
+func TestScorers(t *testing.T) {
+	weights := DefaultConfig().Events
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	video := func(views, likes int, createdAt time.Time) models.Video {
+		return models.Video{Views: views, Likes: likes, CreatedAt: createdAt}
+	}
+
+	tests := []struct {
+		name   string
+		scorer Scorer
+		in     Input
+		want   float64
+	}{
+		{"linear weighs the delta", Linear{}, Input{Delta: models.VideoDelta{Views: 2, Likes: 1, WatchTime: 10}, Video: video(50, 50, now)}, 7.5},
+		{"linear of no engagement", Linear{}, Input{}, 0},
+		{"reddit hot grows with the order of engagement", RedditHot{}, Input{Video: video(100, 0, redditEpoch.Add(45000*time.Second))}, 3},
+		{"reddit hot of no engagement is the age bonus", RedditHot{}, Input{Video: video(0, 0, redditEpoch.Add(90000*time.Second))}, 2},
+		{"wilson of no views", Wilson{Z: 1.96}, Input{Video: video(0, 0, now)}, 0},
+		{"wilson of one like in one view", Wilson{Z: 1.96}, Input{Video: video(1, 1, now)}, 0.206543},
+		{"wilson of a hundred likes in a hundred views", Wilson{Z: 1.96}, Input{Video: video(100, 100, now)}, 0.963005},
+		{"wilson of half the views liked", Wilson{Z: 1.96}, Input{Video: video(100, 50, now)}, 0.403830},
+		{"wilson counts likes without views as views", Wilson{Z: 1.96}, Input{Video: video(0, 1, now)}, 0.206543},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.Weights = weights
+			if got := tt.scorer.Score(tt.in); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("Score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScorerByName(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantKind Kind
+		wantErr  bool
+	}{
+		{LinearScorerName, Incremental, false},
+		{RedditHotScorerName, Absolute, false},
+		{WilsonScorerName, Absolute, false},
+		{"", 0, true},
+		{"pagerank", 0, true},
+	}
+	for _, tt := range tests {
+		scorer, err := ScorerByName(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ScorerByName(%q) succeeded, want error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ScorerByName(%q): %v", tt.name, err)
+			continue
+		}
+		if scorer.Name() != tt.name || scorer.Kind() != tt.wantKind {
+			t.Errorf("ScorerByName(%q) = %s of kind %v, want kind %v", tt.name, scorer.Name(), scorer.Kind(), tt.wantKind)
+		}
+	}
+}
+
+func TestScorerFor(t *testing.T) {
+	config := DefaultConfig()
+	config.Scorers = map[models.RankingMode]string{models.RankingModeAllTime: WilsonScorerName}
+
+	if got := config.ScorerFor(models.RankingModeAllTime).Name(); got != WilsonScorerName {
+		t.Errorf("ScorerFor(all_time) = %s, want %s", got, WilsonScorerName)
+	}
+	if got := config.ScorerFor(models.RankingModeHot).Name(); got != LinearScorerName {
+		t.Errorf("ScorerFor(hot) = %s, want the %s default", got, LinearScorerName)
+	}
+}
+
+func TestVideoScore(t *testing.T) {
+	weights := DefaultConfig().Events
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	video := models.Video{Views: 100, Likes: 50, Comments: 2, Shares: 1, WatchTime: 600, CreatedAt: now}
+
+	tests := []struct {
+		scorer Scorer
+		want   float64
+	}{
+		// The linear total is the weighted sum of every counter.
+		{Linear{}, 100*weights.View + 50*weights.Like + 2*weights.Comment + 1*weights.Share + 600*weights.WatchSecond},
+		{Wilson{Z: 1.96}, Wilson{Z: 1.96}.Score(Input{Video: video})},
+	}
+	for _, tt := range tests {
+		if got := VideoScore(tt.scorer, video, weights); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("VideoScore(%s) = %v, want %v", tt.scorer.Name(), got, tt.want)
+		}
+	}
+}
@@ -2,68 +2,462 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"realtime-ranking/eventbus"
+	"realtime-ranking/events"
 	"realtime-ranking/models"
+	"realtime-ranking/scoring"
 	"realtime-ranking/store"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/segmentio/kafka-go"
 )
 
+// ErrVideoNotFound is returned (wrapped) when the requested video does not exist.
+var ErrVideoNotFound = store.ErrVideoNotFound
+
+// ErrVideoNotRanked is returned (wrapped) when a video exists but is missing from the
+// all-time leaderboard, for example because adding it to the ranking index failed.
+var ErrVideoNotRanked = errors.New("video is not ranked")
+
+// VideoEventsTopic is the event bus topic video events are published to.
+const VideoEventsTopic = "video-events"
+
 type RankingService struct {
-	redisStore    *store.RedisStore
-	postgresStore *store.PostgresStore
-	kafkaWriter   *kafka.Writer
+	repository   store.VideoRepository
+	rankingIndex store.RankingIndex
+	publisher    eventbus.Publisher
+	// eventOutbox, when set, receives events instead of publisher; a relay then
+	// publishes them to the event bus.
+	eventOutbox store.EventOutbox
+	eventFormat events.Format
+	scoring     *scoring.Provider
+	// labelKinds lists the kinds of labels that get their own leaderboards.
+	labelKinds        []models.LabelKind
+	creatorTopK       int
+	audienceMinVideos int64
+	// rescoreMu keeps re-scoring runs from overlapping.
+	rescoreMu sync.Mutex
+}
+
+// DefaultAudienceMinVideos is how many videos a region or language leaderboard needs
+// before it is served instead of the global one.
+const DefaultAudienceMinVideos = 10
+
+// LeaderboardConfig configures the leaderboards kept besides the global ones. Every
+// category and creator always has its own leaderboard.
+type LeaderboardConfig struct {
+	// TagLeaderboards also keeps a leaderboard per tag.
+	TagLeaderboards bool
+	// CreatorTopK, when positive, makes a creator's score the mean of their
+	// CreatorTopK highest video scores instead of the sum of all of them.
+	CreatorTopK int
+	// AudienceMinVideos is how many videos a region or language leaderboard needs
+	// before it is served; smaller ones fall back to the global leaderboard.
+	AudienceMinVideos int
+}
+
+// NewRankingService creates the service. eventOutbox is optional: when nil, events
+// are published straight to publisher. Events are encoded in eventFormat.
+func NewRankingService(repository store.VideoRepository, rankingIndex store.RankingIndex, publisher eventbus.Publisher, eventOutbox store.EventOutbox, eventFormat events.Format, scoringProvider *scoring.Provider, leaderboards LeaderboardConfig) *RankingService {
+	labelKinds := []models.LabelKind{models.LabelKindCategory}
+	if leaderboards.TagLeaderboards {
+		labelKinds = append(labelKinds, models.LabelKindTag)
+	}
+	return &RankingService{repository: repository, rankingIndex: rankingIndex, publisher: publisher, eventOutbox: eventOutbox, eventFormat: eventFormat, scoring: scoringProvider, labelKinds: labelKinds, creatorTopK: leaderboards.CreatorTopK, audienceMinVideos: int64(leaderboards.AudienceMinVideos)}
+}
+
+// HasLabelLeaderboards reports whether labels of kind have their own leaderboards.
+func (rs *RankingService) HasLabelLeaderboards(kind models.LabelKind) bool {
+	for _, labelKind := range rs.labelKinds {
+		if labelKind == kind {
+			return true
+		}
+	}
+	return false
 }
 
-func NewRankingService(redisStore *store.RedisStore, postgresStore *store.PostgresStore, kafkaWriter *kafka.Writer) *RankingService {
-	return &RankingService{redisStore: redisStore, postgresStore: postgresStore, kafkaWriter: kafkaWriter}
+// ScoringConfig returns the scoring weights currently in effect.
+func (rs *RankingService) ScoringConfig() scoring.Config {
+	return rs.scoring.Current()
+}
+
+// ReloadScoringConfig re-reads the scoring config source and activates it for all
+// subsequent events and personalized rankings. If the version changed, videos are
+// re-scored with the new config in the background.
+func (rs *RankingService) ReloadScoringConfig() (scoring.Config, error) {
+	previous := rs.scoring.Current()
+	config, err := rs.scoring.Reload()
+	if err != nil {
+		return scoring.Config{}, fmt.Errorf("error reloading scoring config: %w", err)
+	}
+	log.Printf("Activated scoring config version %s", config.Version)
+	if config.Version != previous.Version {
+		rs.StartRescore()
+	}
+	return config, nil
 }
 
 func (rs *RankingService) CreateVideo(ctx context.Context, video *models.Video) error {
-	err := rs.postgresStore.CreateVideo(ctx, video)
+	err := rs.repository.CreateVideo(ctx, video)
 	if err != nil {
-		return fmt.Errorf("error creating video in postgres: %w", err)
+		return fmt.Errorf("error creating video in repository: %w", err)
 	}
-	if err := rs.updateVideoInRedis(ctx, video); err != nil {
-		log.Printf("Error updating video in Redis: %v", err)
+	if err := rs.updateVideoInIndex(ctx, video); err != nil {
+		log.Printf("Error updating video in ranking index: %v", err)
 	}
+	rs.updateLabelScores(ctx, video)
+	rs.updateCreatorScore(ctx, video)
 	return nil
 }
 
-func (rs *RankingService) UpdateVideo(ctx context.Context, video *models.Video) error {
-	err := rs.postgresStore.UpdateVideo(ctx, video)
+// UpdateVideo applies a metadata-only update. Engagement counters and scores are not
+// touched; if the categories or tags change, the video moves to the leaderboards of
+// its new labels.
+func (rs *RankingService) UpdateVideo(ctx context.Context, videoID uuid.UUID, update models.VideoMetadataUpdate) (*models.Video, error) {
+	var previous *models.Video
+	if update.Categories != nil || update.Tags != nil {
+		var err error
+		previous, err = rs.repository.GetVideo(ctx, videoID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting video from repository: %w", err)
+		}
+	}
+
+	video, err := rs.repository.UpdateVideoMetadata(ctx, videoID, update)
 	if err != nil {
-		return fmt.Errorf("error updating video in postgres: %w", err)
+		return nil, fmt.Errorf("error updating video in repository: %w", err)
 	}
-	if err := rs.updateVideoInRedis(ctx, video); err != nil {
-		log.Printf("Error updating video in Redis: %v", err)
+
+	if previous != nil {
+		for _, kind := range rs.labelKinds {
+			removed := missingLabels(previous.Labels(kind), video.Labels(kind))
+			if err := rs.rankingIndex.RemoveLabelScores(ctx, kind, removed, video.ID); err != nil {
+				log.Printf("Error removing video from %s leaderboards: %v", kind, err)
+			}
+		}
+		rs.updateLabelScores(ctx, video)
+	}
+	return video, nil
+}
+
+func (rs *RankingService) updateVideoInIndex(ctx context.Context, video *models.Video) error {
+	return rs.rankingIndex.UpdateVideoScore(ctx, video.ID, video.Score)
+}
+
+// updateLabelScores copies the video's all-time score to the leaderboards of its
+// labels. Failures are logged; the next score change repairs them.
+func (rs *RankingService) updateLabelScores(ctx context.Context, video *models.Video) {
+	for _, kind := range rs.labelKinds {
+		if err := rs.rankingIndex.SetLabelScores(ctx, kind, video.Labels(kind), video.ID, video.Score); err != nil {
+			log.Printf("Error updating video in %s leaderboards: %v", kind, err)
+		}
+	}
+}
+
+// updateCreatorScore records the video's all-time score under its creator, which also
+// updates the creator's aggregate score. Failures are logged like updateLabelScores.
+func (rs *RankingService) updateCreatorScore(ctx context.Context, video *models.Video) {
+	if video.CreatorID == "" {
+		return
+	}
+	if err := rs.rankingIndex.SetCreatorVideoScore(ctx, video.CreatorID, video.ID, video.Score, rs.creatorTopK); err != nil {
+		log.Printf("Error updating creator %s in creator leaderboard: %v", video.CreatorID, err)
 	}
-	return nil
 }
 
-func (rs *RankingService) updateVideoInRedis(ctx context.Context, video *models.Video) error {
-	return rs.redisStore.UpdateVideoScore(ctx, video.ID, video.Score)
+// missingLabels returns the labels in previous that are not in current.
+func missingLabels(previous, current []string) []string {
+	var missing []string
+	for _, label := range previous {
+		found := false
+		for _, other := range current {
+			if other == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, label)
+		}
+	}
+	return missing
 }
 
-func (rs *RankingService) GetTopVideos(ctx context.Context, start, stop int64) ([]models.Video, error) {
-	redisVideos, err := rs.redisStore.GetTopVideos(ctx, start, stop)
+// ApplyVideoDelta atomically increments the video's engagement counters in the
+// repository and updates every leaderboard using the scorer configured for it,
+// returning the updated video. delta.ScoringVersion is filled in here.
+// occurredAt is when the engagement happened and decides which hot weight and window
+// buckets it counts towards; it is capped at the current time.
+func (rs *RankingService) ApplyVideoDelta(ctx context.Context, delta models.VideoDelta, occurredAt time.Time) (*models.Video, error) {
+	config := rs.scoring.Current()
+	now := time.Now().UTC()
+	if occurredAt.IsZero() || occurredAt.After(now) {
+		occurredAt = now
+	}
+	input := scoring.Input{Delta: delta, Weights: config.Events}
+
+	// The all-time score is recomputed from the updated counters in the same write,
+	// so it never mixes contributions scored under different configs.
+	allTimeScorer := config.ScorerFor(models.RankingModeAllTime)
+	delta.ScoringVersion = config.Version
+	video, err := rs.repository.IncrementVideoCounters(ctx, delta, videoScoreFunc(config))
 	if err != nil {
-		return nil, fmt.Errorf("error getting top videos from redis: %w", err)
+		return nil, fmt.Errorf("error incrementing video counters in repository: %w", err)
+	}
+	input.Video = *video
+
+	// The counters are committed from here on, so failures are logged rather than
+	// returned: returning an error would make the caller retry and count the events twice.
+	if err := rs.updateVideoInIndex(ctx, video); err != nil {
+		log.Printf("Error setting video score in ranking index: %v", err)
 	}
 
-	videos := make([]models.Video, len(redisVideos))
-	for i, rv := range redisVideos {
-		video, err := rs.postgresStore.GetVideo(ctx, rv.ID)
+	hotScorer := config.ScorerFor(models.RankingModeHot)
+	if err := rs.rankingIndex.IncrementHotScore(ctx, video.ID, hotScorer.Score(input), occurredAt); err != nil {
+		log.Printf("Error updating hot score in ranking index: %v", err)
+	}
+
+	// Windowed leaderboards sum score gained over time, so they need a delta even when
+	// the all-time leaderboard uses an absolute scorer.
+	windowDelta := scoring.Linear{}.Score(input)
+	if allTimeScorer.Kind() == scoring.Incremental {
+		windowDelta = allTimeScorer.Score(input)
+	}
+	if err := rs.rankingIndex.IncrementWindowScores(ctx, video.ID, windowDelta, occurredAt); err != nil {
+		log.Printf("Error incrementing window scores in ranking index: %v", err)
+	}
+
+	// Audience leaderboards, like windowed ones, sum the score gained from the
+	// audience's events.
+	if len(delta.Audiences) > 0 {
+		audienceDeltas := make(map[models.Audience]float64, len(delta.Audiences))
+		for audience, audienceDelta := range delta.Audiences {
+			audienceInput := scoring.Input{Delta: audienceDelta, Weights: config.Events}
+			if allTimeScorer.Kind() == scoring.Incremental {
+				audienceDeltas[audience] = allTimeScorer.Score(audienceInput)
+			} else {
+				audienceDeltas[audience] = scoring.Linear{}.Score(audienceInput)
+			}
+		}
+		if err := rs.rankingIndex.IncrementAudienceScores(ctx, video.ID, audienceDeltas); err != nil {
+			log.Printf("Error incrementing audience scores in ranking index: %v", err)
+		}
+	}
+
+	rs.updateLabelScores(ctx, video)
+	rs.updateCreatorScore(ctx, video)
+	return video, nil
+}
+
+// videoScoreFunc computes a video's all-time score from its counters under config.
+func videoScoreFunc(config scoring.Config) store.ScoreFunc {
+	scorer := config.ScorerFor(models.RankingModeAllTime)
+	return func(video models.Video) float64 {
+		return scoring.VideoScore(scorer, video, config.Events)
+	}
+}
+
+// rescoreBatchSize is how many videos RescoreVideos lists at a time.
+const rescoreBatchSize = 500
+
+// RescoreVideos recomputes the all-time score of every video last scored under a
+// different scoring config version, and updates the leaderboards derived from it. It
+// returns how many videos were re-scored. Windowed, hot and audience leaderboards are
+// left alone: they only hold recent engagement and age out by themselves.
+func (rs *RankingService) RescoreVideos(ctx context.Context) (int, error) {
+	rs.rescoreMu.Lock()
+	defer rs.rescoreMu.Unlock()
+
+	config := rs.scoring.Current()
+	rescored := 0
+	var after uuid.UUID
+	for {
+		videoIDs, err := rs.repository.ListVideosScoredWithOtherVersions(ctx, config.Version, after, rescoreBatchSize)
 		if err != nil {
-			log.Printf("Error fetching video %s from Postgres: %v", rv.ID, err)
-			continue
+			return rescored, fmt.Errorf("error listing videos to re-score: %w", err)
+		}
+		if len(videoIDs) == 0 {
+			return rescored, nil
+		}
+		for _, videoID := range videoIDs {
+			delta := models.VideoDelta{VideoID: videoID, ScoringVersion: config.Version}
+			video, err := rs.repository.IncrementVideoCounters(ctx, delta, videoScoreFunc(config))
+			if err != nil {
+				return rescored, fmt.Errorf("error re-scoring video %s: %w", videoID, err)
+			}
+			if err := rs.updateVideoInIndex(ctx, video); err != nil {
+				log.Printf("Error setting video score in ranking index: %v", err)
+			}
+			rs.updateLabelScores(ctx, video)
+			rs.updateCreatorScore(ctx, video)
+			rescored++
+		}
+		after = videoIDs[len(videoIDs)-1]
+	}
+}
+
+// StartRescore runs RescoreVideos in the background and logs the outcome.
+func (rs *RankingService) StartRescore() {
+	go func() {
+		version := rs.scoring.Current().Version
+		rescored, err := rs.RescoreVideos(context.Background())
+		if err != nil {
+			log.Printf("Error re-scoring videos for scoring config version %s after %d video(s): %v", version, rescored, err)
+			return
+		}
+		if rescored > 0 {
+			log.Printf("Re-scored %d video(s) with scoring config version %s", rescored, version)
 		}
-		videos[i] = *video
+	}()
+}
+
+// GetTopVideos returns videos from the leaderboard selected by mode. Each video's Score
+// is the leaderboard score, which for hot mode is the decayed score at the time of the call.
+func (rs *RankingService) GetTopVideos(ctx context.Context, mode models.RankingMode, start, stop int64) ([]models.Video, error) {
+	rankedVideos, err := rs.rankingIndex.GetTopVideos(ctx, mode, start, stop)
+	if err != nil {
+		return nil, fmt.Errorf("error getting top videos from ranking index: %w", err)
+	}
+	return rs.hydrateRankedVideos(ctx, rankedVideos)
+}
+
+// GetVideoRank returns the video's position in the all-time leaderboard.
+func (rs *RankingService) GetVideoRank(ctx context.Context, videoID uuid.UUID) (*models.VideoRank, error) {
+	rank, ranked, err := rs.rankingIndex.GetVideoRank(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting video rank from ranking index: %w", err)
+	}
+	if !ranked {
+		if _, err := rs.GetVideo(ctx, videoID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrVideoNotRanked, videoID)
+	}
+	rank.Percentile = float64(rank.Total-rank.Rank) / float64(rank.Total) * 100
+	return &rank, nil
+}
+
+// GetVideoNeighbors returns the video's rank with up to radius videos ranked directly
+// above and below it in the all-time leaderboard.
+func (rs *RankingService) GetVideoNeighbors(ctx context.Context, videoID uuid.UUID, radius int64) (*models.VideoNeighbors, error) {
+	rank, err := rs.GetVideoRank(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := rank.Rank - radius
+	if start < 0 {
+		start = 0
+	}
+	videos, err := rs.GetTopVideos(ctx, models.RankingModeAllTime, start, rank.Rank+radius)
+	if err != nil {
+		return nil, err
+	}
+
+	// The leaderboard may have moved since the rank was read, so split the range
+	// around the video itself rather than at its old rank.
+	split := -1
+	for i := range videos {
+		if videos[i].ID == videoID {
+			split = i
+			break
+		}
+	}
+	neighbors := &models.VideoNeighbors{Rank: *rank, Above: []models.Video{}, Below: []models.Video{}}
+	if split < 0 {
+		log.Printf("Video %s moved out of its neighborhood while it was read", videoID)
+		return neighbors, nil
+	}
+	above, below := videos[:split], videos[split+1:]
+	if int64(len(above)) > radius {
+		above = above[int64(len(above))-radius:]
+	}
+	if int64(len(below)) > radius {
+		below = below[:radius]
+	}
+	neighbors.Above = append(neighbors.Above, above...)
+	neighbors.Below = append(neighbors.Below, below...)
+	return neighbors, nil
+}
+
+// GetTopVideosForLabels returns the top videos among those carrying any of labels,
+// ranked by their all-time score.
+func (rs *RankingService) GetTopVideosForLabels(ctx context.Context, kind models.LabelKind, labels []string, start, stop int64) ([]models.Video, error) {
+	rankedVideos, err := rs.rankingIndex.GetTopVideosForLabels(ctx, kind, labels, start, stop)
+	if err != nil {
+		return nil, fmt.Errorf("error getting top videos for %ss %v from ranking index: %w", kind, labels, err)
+	}
+	return rs.hydrateRankedVideos(ctx, rankedVideos)
+}
+
+// GetTopVideosForAudience returns videos ranked by the score gained from the audience's
+// events. If fewer than the configured minimum of videos have been ranked for the
+// audience, the all-time leaderboard is returned instead and global is true.
+func (rs *RankingService) GetTopVideosForAudience(ctx context.Context, audience models.Audience, start, stop int64) (_ []models.Video, global bool, err error) {
+	rankedVideos, total, err := rs.rankingIndex.GetTopVideosForAudience(ctx, audience, start, stop)
+	if err != nil {
+		return nil, false, fmt.Errorf("error getting top videos for %s from ranking index: %w", audience, err)
+	}
+	if total < rs.audienceMinVideos {
+		videos, err := rs.GetTopVideos(ctx, models.RankingModeAllTime, start, stop)
+		return videos, true, err
+	}
+	videos, err := rs.hydrateRankedVideos(ctx, rankedVideos)
+	return videos, false, err
+}
+
+// GetTopCreators returns creators ordered by their aggregate score.
+func (rs *RankingService) GetTopCreators(ctx context.Context, start, stop int64) ([]models.CreatorScore, error) {
+	creators, err := rs.rankingIndex.GetTopCreators(ctx, start, stop)
+	if err != nil {
+		return nil, fmt.Errorf("error getting top creators from ranking index: %w", err)
+	}
+	return creators, nil
+}
+
+// GetTopCreatorVideos returns the creator's videos ranked by their all-time score.
+func (rs *RankingService) GetTopCreatorVideos(ctx context.Context, creatorID string, start, stop int64) ([]models.Video, error) {
+	rankedVideos, err := rs.rankingIndex.GetTopCreatorVideos(ctx, creatorID, start, stop)
+	if err != nil {
+		return nil, fmt.Errorf("error getting top videos of creator %s from ranking index: %w", creatorID, err)
+	}
+	return rs.hydrateRankedVideos(ctx, rankedVideos)
+}
+
+// GetTopVideosInWindow returns the videos with the most score gained within window.
+func (rs *RankingService) GetTopVideosInWindow(ctx context.Context, window models.RankingWindow, start, stop int64) ([]models.Video, error) {
+	rankedVideos, err := rs.rankingIndex.GetTopVideosInWindow(ctx, window, start, stop)
+	if err != nil {
+		return nil, fmt.Errorf("error getting top videos for window %s from ranking index: %w", window, err)
+	}
+	return rs.hydrateRankedVideos(ctx, rankedVideos)
+}
+
+// hydrateRankedVideos loads the full videos for leaderboard entries, keeping rank order
+// and the leaderboard score.
+func (rs *RankingService) hydrateRankedVideos(ctx context.Context, rankedVideos []models.Video) ([]models.Video, error) {
+	videoIDs := make([]uuid.UUID, len(rankedVideos))
+	scores := make(map[uuid.UUID]float64, len(rankedVideos))
+	for i, rv := range rankedVideos {
+		videoIDs[i] = rv.ID
+		scores[rv.ID] = rv.Score
+	}
+
+	videos, err := rs.repository.GetVideos(ctx, videoIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching top videos from repository: %w", err)
+	}
+	if len(videos) < len(videoIDs) {
+		log.Printf("%d ranked video(s) are missing from the repository", len(videoIDs)-len(videos))
+	}
+	for i := range videos {
+		videos[i].Score = scores[videos[i].ID]
 	}
 
 	return videos, nil
@@ -71,7 +465,7 @@ func (rs *RankingService) GetTopVideos(ctx context.Context, start, stop int64) (
 
 func (rs *RankingService) GetTopVideosPerUser(ctx context.Context, userID string, start, stop int64) ([]models.Video, error) {
 	// 1.  Try to get user preferences from cache
-	cachedPreferences, err := rs.redisStore.GetCachedUserPreferences(ctx, userID)
+	cachedPreferences, err := rs.rankingIndex.GetCachedUserPreferences(ctx, userID)
 	if err != nil {
 		log.Printf("Error getting cached user preferences: %v", err)
 	}
@@ -80,29 +474,29 @@ func (rs *RankingService) GetTopVideosPerUser(ctx context.Context, userID string
 	if cachedPreferences != nil {
 		userPreferences = cachedPreferences
 	} else {
-		// 2.  If not in cache, get from Postgres
-		prefs, err := rs.postgresStore.GetUserPreferences(ctx, userID)
+		// 2.  If not in cache, get from the repository
+		prefs, err := rs.repository.GetUserPreferences(ctx, userID)
 		if err != nil {
-			log.Printf("Error fetching user preferences from Postgres: %v", err)
+			log.Printf("Error fetching user preferences from repository: %v", err)
 			prefs = &models.UserPreference{UserID: userID} // Default to empty preferences
 		}
 		userPreferences = prefs
 
 		// 3.  Cache the preferences (with a TTL)
-		cacheErr := rs.redisStore.CacheUserPreferences(ctx, userID, *userPreferences, time.Hour)
+		cacheErr := rs.rankingIndex.CacheUserPreferences(ctx, userID, *userPreferences, time.Hour)
 		if cacheErr != nil {
 			log.Printf("Error caching user preferences: %v", cacheErr)
 		}
 	}
 
 	// 4.  Get user's video interaction history
-	userInteractions, err := rs.postgresStore.GetUserVideoInteractions(ctx, userID)
+	userInteractions, err := rs.repository.GetUserVideoInteractions(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching user video interactions: %w", err)
 	}
 
 	// 5.  Get global top videos (consider caching this too)
-	globalTopVideos, err := rs.GetTopVideos(ctx, 0, 100) // Fetch a larger set to filter
+	globalTopVideos, err := rs.GetTopVideos(ctx, models.RankingModeAllTime, 0, 100) // Fetch a larger set to filter
 	if err != nil {
 		return nil, fmt.Errorf("error fetching global top videos: %w", err)
 	}
@@ -126,35 +520,30 @@ func (rs *RankingService) personalizeVideoRanking(videos []models.Video, userInt
 		interactionMap[interaction.VideoID] = interaction
 	}
 
-	videoCategoryMap := rs.getVideoCategoryMap(videos)
+	weights := rs.scoring.Current().Personalization
 
 	for i := range videos {
 		interaction, exists := interactionMap[videos[i].ID]
 		if exists {
 			// Apply boosts based on user interaction
-			videos[i].Score += float64(interaction.Views) * 0.1
-			videos[i].Score += float64(interaction.Likes) * 0.5
-			videos[i].Score += float64(interaction.Comments) * 0.8
-			videos[i].Score += float64(interaction.Shares) * 1.2
-			videos[i].Score += float64(interaction.WatchTime) * 0.05
+			videos[i].Score += float64(interaction.Views) * weights.View
+			videos[i].Score += float64(interaction.Likes) * weights.Like
+			videos[i].Score += float64(interaction.Comments) * weights.Comment
+			videos[i].Score += float64(interaction.Shares) * weights.Share
+			videos[i].Score += float64(interaction.WatchTime) * weights.WatchSecond
 
 			// Apply a recency boost
 			timeDiff := time.Since(interaction.LastViewed).Hours()
-			recencyBoost := 1.0 / (1.0 + timeDiff/24.0)
+			recencyBoost := 1.0 / (1.0 + timeDiff/weights.RecencyHours)
 			videos[i].Score *= recencyBoost
 		}
 
 		// Apply boosts based on user preferences
-		if len(userPreferences.Categories) > 0 {
-			videoCategories, ok := videoCategoryMap[videos[i].ID]
-			if ok {
-				for _, userCategory := range userPreferences.Categories {
-					for _, videoCategory := range videoCategories {
-						if userCategory == videoCategory {
-							videos[i].Score += 3.0
-							break
-						}
-					}
+		for _, userCategory := range userPreferences.Categories {
+			for _, videoCategory := range videos[i].Categories {
+				if strings.EqualFold(strings.TrimSpace(userCategory), videoCategory) {
+					videos[i].Score += weights.CategoryMatch
+					break
 				}
 			}
 		}
@@ -167,47 +556,92 @@ func (rs *RankingService) personalizeVideoRanking(videos []models.Video, userInt
 	return videos
 }
 
-// Dummy implementation - replace with actual logic to fetch categories from DB or another service
-func (rs *RankingService) getVideoCategoryMap(videos []models.Video) map[uuid.UUID][]string {
-	categoryMap := make(map[uuid.UUID][]string)
-	for _, video := range videos {
-		categoryMap[video.ID] = []string{"default"} // Placeholder
+// ClaimEvent records that an event is being processed. It returns false if the event
+// was already claimed within ttl, meaning it is a duplicate delivery.
+func (rs *RankingService) ClaimEvent(ctx context.Context, eventID string, ttl time.Duration) (bool, error) {
+	claimed, err := rs.rankingIndex.ClaimEvent(ctx, eventID, ttl)
+	if err != nil {
+		return false, fmt.Errorf("error claiming event %s: %w", eventID, err)
 	}
-	return categoryMap
+	return claimed, nil
 }
 
+// ConfirmEvent keeps the claim on an applied event for ttl, so redeliveries within ttl
+// are dropped as duplicates.
+func (rs *RankingService) ConfirmEvent(ctx context.Context, eventID string, ttl time.Duration) error {
+	if err := rs.rankingIndex.ConfirmEvent(ctx, eventID, ttl); err != nil {
+		return fmt.Errorf("error confirming event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// ReleaseEvent drops the claim on an event that failed so a redelivery is processed.
+func (rs *RankingService) ReleaseEvent(ctx context.Context, eventID string) error {
+	if err := rs.rankingIndex.ReleaseEvent(ctx, eventID); err != nil {
+		return fmt.Errorf("error releasing event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// PublishVideoEvent publishes the event to the event bus, or to the outbox when one is
+// configured, assigning an event ID and timestamp if the caller did not supply them.
+// The event is stamped with the current schema version and encoded in the configured
+// format. With an outbox, a nil error means the event is durably stored.
 func (rs *RankingService) PublishVideoEvent(ctx context.Context, event *models.VideoEvent) error {
-	eventBytes, err := json.Marshal(event)
+	event.SchemaVersion = models.VideoEventSchemaVersion
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	eventBytes, err := events.Encode(event, rs.eventFormat)
 	if err != nil {
-		return fmt.Errorf("error marshaling video event: %w", err)
+		return fmt.Errorf("error encoding video event: %w", err)
+	}
+
+	if rs.eventOutbox != nil {
+		err := rs.eventOutbox.EnqueueOutboxMessage(ctx, models.OutboxMessage{
+			Topic:       VideoEventsTopic,
+			Key:         event.VideoID.String(),
+			Payload:     eventBytes,
+			ContentType: string(rs.eventFormat),
+		})
+		if err != nil {
+			return fmt.Errorf("error writing video event to outbox: %w", err)
+		}
+		return nil
 	}
 
-	msg := kafka.Message{
-		Key:   []byte(event.VideoID.String()),
-		Value: eventBytes,
+	msg := eventbus.Message{
+		Topic:   VideoEventsTopic,
+		Key:     []byte(event.VideoID.String()),
+		Value:   eventBytes,
+		Headers: []eventbus.Header{{Key: events.ContentTypeHeader, Value: []byte(rs.eventFormat)}},
 	}
 
-	if err := rs.kafkaWriter.WriteMessages(ctx, msg); err != nil {
-		return fmt.Errorf("error writing message to kafka: %w", err)
+	if err := rs.publisher.Publish(ctx, msg); err != nil {
+		return fmt.Errorf("error publishing video event: %w", err)
 	}
 	return nil
 }
 
 func (rs *RankingService) GetVideo(ctx context.Context, videoID uuid.UUID) (*models.Video, error) {
-	video, err := rs.postgresStore.GetVideo(ctx, videoID)
+	video, err := rs.repository.GetVideo(ctx, videoID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting video from postgres: %w", err)
+		return nil, fmt.Errorf("error getting video from repository: %w", err)
 	}
 	return video, nil
 }
 
 func (rs *RankingService) UpdateUserPreferences(ctx context.Context, preferences *models.UserPreference) error {
-	if err := rs.postgresStore.UpdateUserPreferences(ctx, preferences); err != nil {
-		return fmt.Errorf("error updating user preferences in postgres: %w", err)
+	if err := rs.repository.UpdateUserPreferences(ctx, preferences); err != nil {
+		return fmt.Errorf("error updating user preferences in repository: %w", err)
 	}
 
 	// Invalidate cache
-	err := rs.redisStore.DeleteCachedUserPreferences(ctx, preferences.UserID)
+	err := rs.rankingIndex.DeleteCachedUserPreferences(ctx, preferences.UserID)
 	if err != nil {
 		log.Printf("Error deleting cached user preferences: %v", err) // Log, but don't fail
 	}
@@ -216,8 +650,8 @@ func (rs *RankingService) UpdateUserPreferences(ctx context.Context, preferences
 }
 
 func (rs *RankingService) UpdateUserVideoInteraction(ctx context.Context, interaction *models.UserVideoInteraction) error {
-	if err := rs.postgresStore.UpdateUserVideoInteraction(ctx, interaction); err != nil {
-		return fmt.Errorf("error updating user video interaction in postgres: %w", err)
+	if err := rs.repository.UpdateUserVideoInteraction(ctx, interaction); err != nil {
+		return fmt.Errorf("error updating user video interaction in repository: %w", err)
 	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"realtime-ranking/events"
+	"realtime-ranking/models"
+	"realtime-ranking/scoring"
+	"realtime-ranking/store"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newRankedService returns a service over the memory store with count videos,
+// ranked in the order returned.
+func newRankedService(t *testing.T, count int) (*RankingService, []uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+	provider, err := scoring.NewProvider("")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ms := store.NewMemoryStore(time.Hour)
+	rs := NewRankingService(ms, ms, nil, nil, events.FormatJSON, provider, LeaderboardConfig{})
+
+	ids := make([]uuid.UUID, count)
+	for i := range ids {
+		video := &models.Video{ID: uuid.New(), Title: "ranked"}
+		if err := rs.CreateVideo(ctx, video); err != nil {
+			t.Fatalf("CreateVideo: %v", err)
+		}
+		if _, err := rs.ApplyVideoDelta(ctx, models.VideoDelta{VideoID: video.ID, Likes: count - i}, time.Now()); err != nil {
+			t.Fatalf("ApplyVideoDelta: %v", err)
+		}
+		ids[i] = video.ID
+	}
+	return rs, ids
+}
+
+func videoIDs(videos []models.Video) []uuid.UUID {
+	ids := make([]uuid.UUID, len(videos))
+	for i, video := range videos {
+		ids[i] = video.ID
+	}
+	return ids
+}
+
+func equalIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetVideoRank(t *testing.T) {
+	rs, ids := newRankedService(t, 4)
+	tests := []struct {
+		index          int
+		wantRank       int64
+		wantPercentile float64
+	}{
+		{0, 0, 100},
+		{1, 1, 75},
+		{3, 3, 25},
+	}
+	for _, tt := range tests {
+		rank, err := rs.GetVideoRank(context.Background(), ids[tt.index])
+		if err != nil {
+			t.Fatalf("GetVideoRank(%d): %v", tt.index, err)
+		}
+		if rank.Rank != tt.wantRank || rank.Total != 4 || rank.Percentile != tt.wantPercentile {
+			t.Errorf("GetVideoRank(%d) = rank %d of %d at %v%%, want rank %d of 4 at %v%%",
+				tt.index, rank.Rank, rank.Total, rank.Percentile, tt.wantRank, tt.wantPercentile)
+		}
+	}
+
+	if _, err := rs.GetVideoRank(context.Background(), uuid.New()); !errors.Is(err, ErrVideoNotFound) {
+		t.Errorf("GetVideoRank for an unknown video = %v, want %v", err, ErrVideoNotFound)
+	}
+}
+
+func TestGetVideoNeighbors(t *testing.T) {
+	rs, ids := newRankedService(t, 5)
+	tests := []struct {
+		name                 string
+		index                int
+		radius               int64
+		wantAbove, wantBelow []uuid.UUID
+	}{
+		{"middle", 2, 1, ids[1:2], ids[3:4]},
+		{"middle with a wide radius", 2, 2, ids[0:2], ids[3:5]},
+		{"top has nothing above", 0, 2, ids[:0], ids[1:3]},
+		{"bottom has nothing below", 4, 2, ids[2:4], ids[:0]},
+		{"radius past both ends", 1, 10, ids[0:1], ids[2:5]},
+		{"zero radius", 2, 0, ids[:0], ids[:0]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			neighbors, err := rs.GetVideoNeighbors(context.Background(), ids[tt.index], tt.radius)
+			if err != nil {
+				t.Fatalf("GetVideoNeighbors: %v", err)
+			}
+			if neighbors.Rank.Rank != int64(tt.index) {
+				t.Errorf("rank = %d, want %d", neighbors.Rank.Rank, tt.index)
+			}
+			if got := videoIDs(neighbors.Above); !equalIDs(got, tt.wantAbove) {
+				t.Errorf("above = %v, want %v", got, tt.wantAbove)
+			}
+			if got := videoIDs(neighbors.Below); !equalIDs(got, tt.wantBelow) {
+				t.Errorf("below = %v, want %v", got, tt.wantBelow)
+			}
+		})
+	}
+}
+
+func TestRescoreVideos(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scoring.json")
+	writeConfig := func(contents string) {
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`{"version": "likes", "events": {"view": 1, "like": 10}}`)
+	provider, err := scoring.NewProvider(path)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ms := store.NewMemoryStore(time.Hour)
+	rs := NewRankingService(ms, ms, nil, nil, events.FormatJSON, provider, LeaderboardConfig{})
+
+	liked, viewed := uuid.New(), uuid.New()
+	for _, video := range []*models.Video{{ID: liked}, {ID: viewed}} {
+		if err := rs.CreateVideo(ctx, video); err != nil {
+			t.Fatalf("CreateVideo: %v", err)
+		}
+	}
+	apply := func(delta models.VideoDelta) {
+		t.Helper()
+		if _, err := rs.ApplyVideoDelta(ctx, delta, time.Now()); err != nil {
+			t.Fatalf("ApplyVideoDelta: %v", err)
+		}
+	}
+	apply(models.VideoDelta{VideoID: liked, Views: 2, Likes: 2})
+	apply(models.VideoDelta{VideoID: viewed, Views: 10})
+
+	// Under the new weights views count most. A video touched after the switch is
+	// scored from all of its counters, not just the new ones.
+	writeConfig(`{"version": "views", "events": {"view": 5, "like": 1}}`)
+	if _, err := provider.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	apply(models.VideoDelta{VideoID: liked, Views: 1})
+
+	rescored, err := rs.RescoreVideos(ctx)
+	if err != nil {
+		t.Fatalf("RescoreVideos: %v", err)
+	}
+	if rescored != 1 {
+		t.Errorf("RescoreVideos re-scored %d videos, want 1", rescored)
+	}
+
+	want := map[uuid.UUID]float64{viewed: 50, liked: 3*5 + 2*1}
+	top, err := rs.GetTopVideos(ctx, models.RankingModeAllTime, 0, -1)
+	if err != nil {
+		t.Fatalf("GetTopVideos: %v", err)
+	}
+	if got := videoIDs(top); !equalIDs(got, []uuid.UUID{viewed, liked}) {
+		t.Errorf("leaderboard = %v, want %v", got, []uuid.UUID{viewed, liked})
+	}
+	for _, video := range top {
+		if video.Score != want[video.ID] {
+			t.Errorf("leaderboard score of %s = %v, want %v", video.ID, video.Score, want[video.ID])
+		}
+		stored, err := rs.GetVideo(ctx, video.ID)
+		if err != nil {
+			t.Fatalf("GetVideo: %v", err)
+		}
+		if stored.Score != want[video.ID] || stored.ScoringVersion != "views" {
+			t.Errorf("stored score of %s = %v under version %q, want %v under version views", video.ID, stored.Score, stored.ScoringVersion, want[video.ID])
+		}
+	}
+
+	if rescored, err := rs.RescoreVideos(ctx); err != nil || rescored != 0 {
+		t.Errorf("second RescoreVideos = %d, %v; want nothing left to re-score", rescored, err)
+	}
+}
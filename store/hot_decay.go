@@ -0,0 +1,28 @@
+package store
+
+import (
+	"math"
+	"time"
+)
+
+// Hot scores use forward exponential decay: instead of shrinking every stored score as
+// time passes, each increment is multiplied by 2^((at-epoch)/halfLife) so that newer
+// engagement weighs more. Ordering is therefore correct without touching old entries,
+// and dividing by the same factor at read time yields the decayed value "now".
+//
+// Weights grow without bound, so once they exceed 2^hotRebaseExponent the whole set is
+// scaled down and the epoch moved forward by the same number of half-lives.
+const hotRebaseExponent = 64
+
+// DefaultHotHalfLife is used when no half-life is configured.
+const DefaultHotHalfLife = 24 * time.Hour
+
+// hotExponent returns the number of half-lives between epoch and at.
+func hotExponent(epoch, at time.Time, halfLife time.Duration) float64 {
+	return at.Sub(epoch).Seconds() / halfLife.Seconds()
+}
+
+// hotDecayFactor converts a stored hot score into its decayed value at now.
+func hotDecayFactor(epoch, now time.Time, halfLife time.Duration) float64 {
+	return math.Pow(2, -hotExponent(epoch, now, halfLife))
+}
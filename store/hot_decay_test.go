@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"math"
+	"realtime-ranking/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-3*math.Abs(want)
+}
+
+func TestHotExponentAndDecayFactor(t *testing.T) {
+	epoch := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		at           time.Time
+		halfLife     time.Duration
+		wantExponent float64
+		wantFactor   float64
+	}{
+		{"at the epoch", epoch, time.Hour, 0, 1},
+		{"one half-life later", epoch.Add(time.Hour), time.Hour, 1, 0.5},
+		{"three half-lives later", epoch.Add(3 * 24 * time.Hour), 24 * time.Hour, 3, 0.125},
+		{"half a half-life later", epoch.Add(30 * time.Minute), time.Hour, 0.5, 1 / math.Sqrt2},
+		{"before the epoch", epoch.Add(-2 * time.Hour), time.Hour, -2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hotExponent(epoch, tt.at, tt.halfLife); math.Abs(got-tt.wantExponent) > 1e-9 {
+				t.Errorf("hotExponent = %v, want %v", got, tt.wantExponent)
+			}
+			if got := hotDecayFactor(epoch, tt.at, tt.halfLife); !closeTo(got, tt.wantFactor) {
+				t.Errorf("hotDecayFactor = %v, want %v", got, tt.wantFactor)
+			}
+		})
+	}
+}
+
+func TestHotScoresDecay(t *testing.T) {
+	ctx := context.Background()
+	ms := NewMemoryStore(time.Hour)
+	now := time.Now()
+	older, newer := uuid.New(), uuid.New()
+
+	// The same engagement one half-life apart: the older one counts half as much now.
+	if err := ms.IncrementHotScore(ctx, older, 8, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("IncrementHotScore: %v", err)
+	}
+	if err := ms.IncrementHotScore(ctx, newer, 8, now); err != nil {
+		t.Fatalf("IncrementHotScore: %v", err)
+	}
+
+	top, err := ms.GetTopVideos(ctx, models.RankingModeHot, 0, -1)
+	if err != nil {
+		t.Fatalf("GetTopVideos: %v", err)
+	}
+	if len(top) != 2 || top[0].ID != newer || top[1].ID != older {
+		t.Fatalf("GetTopVideos = %+v, want the newer video first", top)
+	}
+	if !closeTo(top[0].Score, 8) || !closeTo(top[1].Score, 4) {
+		t.Errorf("hot scores = %v, %v; want 8 and 4", top[0].Score, top[1].Score)
+	}
+}
+
+func TestHotScoresRebase(t *testing.T) {
+	ctx := context.Background()
+	ms := NewMemoryStore(time.Second)
+	now := time.Now()
+	older, newer := uuid.New(), uuid.New()
+
+	// 100 half-lives apart, past hotRebaseExponent, so the second increment rebases.
+	start := now.Add(-100 * time.Second)
+	if err := ms.IncrementHotScore(ctx, older, 1, start); err != nil {
+		t.Fatalf("IncrementHotScore: %v", err)
+	}
+	if err := ms.IncrementHotScore(ctx, newer, 1, now); err != nil {
+		t.Fatalf("IncrementHotScore: %v", err)
+	}
+
+	if exponent := hotExponent(ms.hotEpoch, now, ms.hotHalfLife); exponent < 0 || exponent > hotRebaseExponent {
+		t.Errorf("weight exponent after rebase = %v, want it within [0, %d]", exponent, hotRebaseExponent)
+	}
+	if !ms.hotEpoch.After(start) {
+		t.Errorf("epoch = %v, want it moved forward from %v", ms.hotEpoch, start)
+	}
+	for member, score := range ms.hotRanking.scores {
+		if math.IsInf(score, 0) || math.IsNaN(score) {
+			t.Errorf("stored hot score of %s = %v", member, score)
+		}
+	}
+
+	top, err := ms.GetTopVideos(ctx, models.RankingModeHot, 0, -1)
+	if err != nil {
+		t.Fatalf("GetTopVideos: %v", err)
+	}
+	if len(top) != 2 || top[0].ID != newer || top[1].ID != older {
+		t.Fatalf("GetTopVideos = %+v, want the newer video first", top)
+	}
+	if !closeTo(top[0].Score, 1) || !closeTo(top[1].Score, math.Pow(2, -100)) {
+		t.Errorf("hot scores = %v, %v; want 1 and 2^-100", top[0].Score, top[1].Score)
+	}
+}
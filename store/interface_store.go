@@ -2,25 +2,106 @@ package store
 
 import (
 	"context"
+	"errors"
 	"realtime-ranking/models"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-type IStore interface {
+// ErrVideoNotFound is returned (wrapped) when a video does not exist.
+var ErrVideoNotFound = errors.New("video not found")
+
+// ScoreFunc computes a video's all-time score from its counters.
+type ScoreFunc func(video models.Video) float64
+
+// VideoRepository is the durable store of record for videos, user interactions and preferences.
+type VideoRepository interface {
 	CreateVideo(ctx context.Context, video *models.Video) error
-	UpdateVideo(ctx context.Context, video *models.Video) error
+	UpdateVideoMetadata(ctx context.Context, videoID uuid.UUID, update models.VideoMetadataUpdate) (*models.Video, error)
 	GetVideo(ctx context.Context, videoID uuid.UUID) (*models.Video, error)
+	GetVideos(ctx context.Context, videoIDs []uuid.UUID) ([]models.Video, error)
+	// IncrementVideoCounters adds delta's counters to the video and, in the same
+	// transaction, sets its score to score(the updated video) and its scoring version
+	// to delta.ScoringVersion, so the score always matches the counters and the
+	// version that produced it. An empty delta just re-scores the video.
+	IncrementVideoCounters(ctx context.Context, delta models.VideoDelta, score ScoreFunc) (*models.Video, error)
+	// ListVideosScoredWithOtherVersions returns up to limit IDs, in ascending order
+	// and greater than after, of videos whose scoring version is not scoringVersion.
+	ListVideosScoredWithOtherVersions(ctx context.Context, scoringVersion string, after uuid.UUID, limit int) ([]uuid.UUID, error)
 	GetUserVideoInteractions(ctx context.Context, userID string) ([]models.UserVideoInteraction, error)
 	GetUserPreferences(ctx context.Context, userID string) (*models.UserPreference, error)
 	UpdateUserVideoInteraction(ctx context.Context, interaction *models.UserVideoInteraction) error
 	UpdateUserPreferences(ctx context.Context, preferences *models.UserPreference) error
+	Close() error
+}
 
+// RankingIndex holds the video leaderboard and short-lived caches used while ranking.
+type RankingIndex interface {
 	UpdateVideoScore(ctx context.Context, videoID uuid.UUID, score float64) error
-	GetTopVideos(ctx context.Context, start, stop int64) ([]models.Video, error)
+	IncrementVideoScore(ctx context.Context, videoID uuid.UUID, delta float64) (float64, error)
+	IncrementHotScore(ctx context.Context, videoID uuid.UUID, delta float64, at time.Time) error
+	GetTopVideos(ctx context.Context, mode models.RankingMode, start, stop int64) ([]models.Video, error)
+	// GetVideoRank returns the video's 0-based position in the all-time leaderboard,
+	// highest score first, along with its score and the number of ranked videos.
+	// Percentile is left unset. It returns false if the video is not ranked.
+	GetVideoRank(ctx context.Context, videoID uuid.UUID) (models.VideoRank, bool, error)
+	IncrementWindowScores(ctx context.Context, videoID uuid.UUID, delta float64, at time.Time) error
+	GetTopVideosInWindow(ctx context.Context, window models.RankingWindow, start, stop int64) ([]models.Video, error)
+	// SetLabelScores sets the video's score in the leaderboard of each of labels.
+	SetLabelScores(ctx context.Context, kind models.LabelKind, labels []string, videoID uuid.UUID, score float64) error
+	// RemoveLabelScores removes the video from the leaderboard of each of labels.
+	RemoveLabelScores(ctx context.Context, kind models.LabelKind, labels []string, videoID uuid.UUID) error
+	// GetTopVideosForLabels ranks the union of the labels' leaderboards. A video in
+	// several of them is listed once, with its highest score.
+	GetTopVideosForLabels(ctx context.Context, kind models.LabelKind, labels []string, start, stop int64) ([]models.Video, error)
+	// IncrementAudienceScores adds each audience's delta to the video's score in that
+	// audience's leaderboard.
+	IncrementAudienceScores(ctx context.Context, videoID uuid.UUID, deltas map[models.Audience]float64) error
+	// GetTopVideosForAudience returns a range of the audience's leaderboard along with
+	// the number of videos in it.
+	GetTopVideosForAudience(ctx context.Context, audience models.Audience, start, stop int64) ([]models.Video, int64, error)
+	// SetCreatorVideoScore sets the video's score among its creator's videos and
+	// updates the creator's score in the creator leaderboard: the sum of their video
+	// scores, or with topK > 0 the mean of their topK highest.
+	SetCreatorVideoScore(ctx context.Context, creatorID string, videoID uuid.UUID, score float64, topK int) error
+	GetTopCreators(ctx context.Context, start, stop int64) ([]models.CreatorScore, error)
+	GetTopCreatorVideos(ctx context.Context, creatorID string, start, stop int64) ([]models.Video, error)
 	CacheUserPreferences(ctx context.Context, userID string, preferences models.UserPreference, expiration time.Duration) error
 	GetCachedUserPreferences(ctx context.Context, userID string) (*models.UserPreference, error)
 	DeleteCachedUserPreferences(ctx context.Context, userID string) error
+	// ClaimEvent marks eventID as being processed for ttl. It returns false if the event
+	// was already claimed, meaning it is a duplicate.
+	ClaimEvent(ctx context.Context, eventID string, ttl time.Duration) (bool, error)
+	// ConfirmEvent keeps the claim on an event that has been applied for ttl, replacing
+	// the expiry it was claimed with.
+	ConfirmEvent(ctx context.Context, eventID string, ttl time.Duration) error
+	// ReleaseEvent forgets a claim so the event can be retried after a failure.
+	ReleaseEvent(ctx context.Context, eventID string) error
 	Close() error
-}
\ No newline at end of file
+}
+
+// EventOutbox durably queues messages for publishing, so that accepting an event does
+// not depend on Kafka being reachable.
+type EventOutbox interface {
+	EnqueueOutboxMessage(ctx context.Context, message models.OutboxMessage) error
+	// PublishOutboxMessages hands up to limit of the oldest pending messages to publish.
+	// If publish succeeds they are removed; otherwise their attempt count and last
+	// error are recorded and they stay pending. Concurrent callers receive disjoint
+	// messages. It returns the number of messages published.
+	PublishOutboxMessages(ctx context.Context, limit int, publish func(ctx context.Context, messages []models.OutboxMessage) error) (int, error)
+}
+
+// IStore is implemented by backends that can serve as both the repository and the ranking index.
+type IStore interface {
+	VideoRepository
+	RankingIndex
+}
+
+var (
+	_ VideoRepository = (*PostgresStore)(nil)
+	_ EventOutbox     = (*PostgresStore)(nil)
+	_ RankingIndex    = (*RedisStore)(nil)
+	_ IStore          = (*MemoryStore)(nil)
+	_ EventOutbox     = (*MemoryStore)(nil)
+)
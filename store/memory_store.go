@@ -0,0 +1,680 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"realtime-ranking/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore is an in-process implementation of IStore for local runs and tests.
+// All data is lost when the process exits.
+type MemoryStore struct {
+	mu              sync.RWMutex
+	videos          map[uuid.UUID]models.Video
+	interactions    map[string]map[uuid.UUID]models.UserVideoInteraction
+	preferences     map[string]models.UserPreference
+	ranking         *sortedSet
+	hotRanking      *sortedSet
+	hotEpoch        time.Time
+	hotHalfLife     time.Duration
+	buckets         map[bucketSeries]map[int64]*sortedSet
+	labelRankings   map[models.LabelKind]map[string]*sortedSet
+	audienceRanking map[models.Audience]*sortedSet
+	creatorRanking  *sortedSet
+	creatorVideos   map[string]*sortedSet
+	preferenceCache map[string]cachedPreference
+	claimedEvents   map[string]time.Time
+	lastClaimSweep  time.Time
+
+	// The outbox has its own lock because publishing holds it across a Kafka write.
+	outboxMu  sync.Mutex
+	outbox    []models.OutboxMessage
+	outboxSeq int64
+}
+
+type cachedPreference struct {
+	preferences models.UserPreference
+	expiresAt   time.Time
+}
+
+func NewMemoryStore(hotHalfLife time.Duration) *MemoryStore {
+	if hotHalfLife <= 0 {
+		hotHalfLife = DefaultHotHalfLife
+	}
+	return &MemoryStore{
+		videos:          make(map[uuid.UUID]models.Video),
+		interactions:    make(map[string]map[uuid.UUID]models.UserVideoInteraction),
+		preferences:     make(map[string]models.UserPreference),
+		ranking:         newSortedSet(),
+		hotRanking:      newSortedSet(),
+		hotHalfLife:     hotHalfLife,
+		buckets:         make(map[bucketSeries]map[int64]*sortedSet),
+		labelRankings:   make(map[models.LabelKind]map[string]*sortedSet),
+		audienceRanking: make(map[models.Audience]*sortedSet),
+		creatorRanking:  newSortedSet(),
+		creatorVideos:   make(map[string]*sortedSet),
+		preferenceCache: make(map[string]cachedPreference),
+		claimedEvents:   make(map[string]time.Time),
+	}
+}
+
+func (ms *MemoryStore) CreateVideo(ctx context.Context, video *models.Video) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.videos[video.ID]; exists {
+		return fmt.Errorf("error creating video: video %s already exists", video.ID)
+	}
+	video.CreatedAt = time.Now().UTC()
+	video.UpdatedAt = video.CreatedAt
+	stored := *video
+	stored.Categories = append([]string{}, video.Categories...)
+	stored.Tags = append([]string{}, video.Tags...)
+	ms.videos[video.ID] = stored
+	return nil
+}
+
+func (ms *MemoryStore) UpdateVideoMetadata(ctx context.Context, videoID uuid.UUID, update models.VideoMetadataUpdate) (*models.Video, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	video, exists := ms.videos[videoID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+	}
+	if update.Title != nil {
+		video.Title = *update.Title
+	}
+	if update.Data != nil {
+		video.Data = *update.Data
+	}
+	if update.Language != nil {
+		video.Language = *update.Language
+	}
+	if update.DurationSeconds != nil {
+		video.DurationSeconds = *update.DurationSeconds
+	}
+	// Label slices are replaced, never modified in place, so stored videos can share them.
+	if update.Categories != nil {
+		video.Categories = append([]string{}, *update.Categories...)
+	}
+	if update.Tags != nil {
+		video.Tags = append([]string{}, *update.Tags...)
+	}
+	video.UpdatedAt = time.Now().UTC()
+	ms.videos[videoID] = video
+	return &video, nil
+}
+
+func (ms *MemoryStore) GetVideo(ctx context.Context, videoID uuid.UUID) (*models.Video, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	video, exists := ms.videos[videoID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+	}
+	return &video, nil
+}
+
+func (ms *MemoryStore) GetVideos(ctx context.Context, videoIDs []uuid.UUID) ([]models.Video, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	videos := make([]models.Video, 0, len(videoIDs))
+	for _, videoID := range videoIDs {
+		if video, exists := ms.videos[videoID]; exists {
+			videos = append(videos, video)
+		}
+	}
+	return videos, nil
+}
+
+func (ms *MemoryStore) IncrementVideoCounters(ctx context.Context, delta models.VideoDelta, score ScoreFunc) (*models.Video, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	video, exists := ms.videos[delta.VideoID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, delta.VideoID)
+	}
+	video.Views += delta.Views
+	video.Likes += delta.Likes
+	video.Comments += delta.Comments
+	video.Shares += delta.Shares
+	video.WatchTime += delta.WatchTime
+	video.Score = score(video)
+	video.ScoringVersion = delta.ScoringVersion
+	video.UpdatedAt = time.Now().UTC()
+	ms.videos[delta.VideoID] = video
+	return &video, nil
+}
+
+func (ms *MemoryStore) ListVideosScoredWithOtherVersions(ctx context.Context, scoringVersion string, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var videoIDs []uuid.UUID
+	for videoID, video := range ms.videos {
+		if video.ScoringVersion != scoringVersion && videoID.String() > after.String() {
+			videoIDs = append(videoIDs, videoID)
+		}
+	}
+	sort.Slice(videoIDs, func(i, j int) bool { return videoIDs[i].String() < videoIDs[j].String() })
+	if len(videoIDs) > limit {
+		videoIDs = videoIDs[:limit]
+	}
+	return videoIDs, nil
+}
+
+func (ms *MemoryStore) GetUserVideoInteractions(ctx context.Context, userID string) ([]models.UserVideoInteraction, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var interactions []models.UserVideoInteraction
+	for _, interaction := range ms.interactions[userID] {
+		interactions = append(interactions, interaction)
+	}
+	return interactions, nil
+}
+
+func (ms *MemoryStore) GetUserPreferences(ctx context.Context, userID string) (*models.UserPreference, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	preferences, exists := ms.preferences[userID]
+	if !exists {
+		return &models.UserPreference{UserID: userID}, nil
+	}
+	preferences.Categories = append([]string(nil), preferences.Categories...)
+	return &preferences, nil
+}
+
+// UpdateUserVideoInteraction mirrors the upsert semantics of PostgresStore.
+func (ms *MemoryStore) UpdateUserVideoInteraction(ctx context.Context, interaction *models.UserVideoInteraction) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	userInteractions, exists := ms.interactions[interaction.UserID]
+	if !exists {
+		userInteractions = make(map[uuid.UUID]models.UserVideoInteraction)
+		ms.interactions[interaction.UserID] = userInteractions
+	}
+
+	existing, exists := userInteractions[interaction.VideoID]
+	if !exists {
+		userInteractions[interaction.VideoID] = *interaction
+		return nil
+	}
+
+	existing.LastViewed = interaction.LastViewed
+	existing.Views += interaction.Views
+	existing.Likes += interaction.Likes
+	existing.Comments += interaction.Comments
+	existing.Shares += interaction.Shares
+	existing.WatchTime += interaction.WatchTime
+	userInteractions[interaction.VideoID] = existing
+	return nil
+}
+
+func (ms *MemoryStore) UpdateUserPreferences(ctx context.Context, preferences *models.UserPreference) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	stored := *preferences
+	stored.Categories = append([]string(nil), preferences.Categories...)
+	ms.preferences[preferences.UserID] = stored
+	return nil
+}
+
+func (ms *MemoryStore) UpdateVideoScore(ctx context.Context, videoID uuid.UUID, score float64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.ranking.add(videoID.String(), score)
+	return nil
+}
+
+func (ms *MemoryStore) IncrementVideoScore(ctx context.Context, videoID uuid.UUID, delta float64) (float64, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.ranking.incrBy(videoID.String(), delta), nil
+}
+
+// IncrementHotScore applies the same forward decay as RedisStore; see hot_decay.go.
+func (ms *MemoryStore) IncrementHotScore(ctx context.Context, videoID uuid.UUID, delta float64, at time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.hotRanking.incrBy(videoID.String(), delta*ms.hotWeight(at))
+	return nil
+}
+
+// hotWeight returns the forward-decay multiplier for at, rebasing the hot ranking
+// first if needed. Callers must hold ms.mu.
+func (ms *MemoryStore) hotWeight(at time.Time) float64 {
+	if ms.hotEpoch.IsZero() {
+		ms.hotEpoch = at
+	}
+	exponent := hotExponent(ms.hotEpoch, at, ms.hotHalfLife)
+	if exponent > hotRebaseExponent {
+		shift := math.Floor(exponent)
+		ms.hotRanking.scale(math.Pow(2, -shift))
+		ms.hotEpoch = ms.hotEpoch.Add(time.Duration(shift * float64(ms.hotHalfLife)))
+		exponent -= shift
+	}
+	return math.Pow(2, exponent)
+}
+
+func (ms *MemoryStore) GetTopVideos(ctx context.Context, mode models.RankingMode, start, stop int64) ([]models.Video, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	ranking, scale := ms.ranking, 1.0
+	if mode == models.RankingModeHot {
+		ranking = ms.hotRanking
+		if !ms.hotEpoch.IsZero() {
+			scale = hotDecayFactor(ms.hotEpoch, time.Now(), ms.hotHalfLife)
+		}
+	}
+
+	return videosFromMembers(ranking.revRange(start, stop), scale), nil
+}
+
+func (ms *MemoryStore) GetVideoRank(ctx context.Context, videoID uuid.UUID) (models.VideoRank, bool, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	rank, ok := ms.ranking.revRank(videoID.String())
+	if !ok {
+		return models.VideoRank{}, false, nil
+	}
+	return models.VideoRank{
+		VideoID: videoID,
+		Rank:    rank,
+		Score:   ms.ranking.scores[videoID.String()],
+		Total:   int64(len(ms.ranking.scores)),
+	}, true, nil
+}
+
+func (ms *MemoryStore) IncrementWindowScores(ctx context.Context, videoID uuid.UUID, delta float64, at time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	for _, series := range allBucketSeries {
+		buckets, exists := ms.buckets[series]
+		if !exists {
+			buckets = make(map[int64]*sortedSet)
+			ms.buckets[series] = buckets
+		}
+
+		// Drop buckets that no window can read any more.
+		oldest := series.bucketStart(now.Add(-series.ttl()))
+		for bucketStart := range buckets {
+			if bucketStart < oldest {
+				delete(buckets, bucketStart)
+			}
+		}
+
+		bucketStart := series.bucketStart(at)
+		if bucketStart < oldest {
+			continue
+		}
+		bucket, exists := buckets[bucketStart]
+		if !exists {
+			bucket = newSortedSet()
+			buckets[bucketStart] = bucket
+		}
+		bucket.incrBy(videoID.String(), delta)
+	}
+	return nil
+}
+
+func (ms *MemoryStore) GetTopVideosInWindow(ctx context.Context, window models.RankingWindow, start, stop int64) ([]models.Video, error) {
+	series, ok := windowSeries[window]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ranking window %q", window)
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	merged := newSortedSet()
+	for _, bucketStart := range series.bucketStarts(window.Duration(), time.Now()) {
+		if bucket, exists := ms.buckets[series][bucketStart]; exists {
+			merged.union(bucket)
+		}
+	}
+	return videosFromMembers(merged.revRange(start, stop), 1), nil
+}
+
+func (ms *MemoryStore) SetLabelScores(ctx context.Context, kind models.LabelKind, labels []string, videoID uuid.UUID, score float64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	rankings, exists := ms.labelRankings[kind]
+	if !exists {
+		rankings = make(map[string]*sortedSet)
+		ms.labelRankings[kind] = rankings
+	}
+	for _, label := range labels {
+		ranking, exists := rankings[label]
+		if !exists {
+			ranking = newSortedSet()
+			rankings[label] = ranking
+		}
+		ranking.add(videoID.String(), score)
+	}
+	return nil
+}
+
+func (ms *MemoryStore) RemoveLabelScores(ctx context.Context, kind models.LabelKind, labels []string, videoID uuid.UUID) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, label := range labels {
+		if ranking, exists := ms.labelRankings[kind][label]; exists {
+			ranking.remove(videoID.String())
+			// Like Redis, drop leaderboards that become empty.
+			if len(ranking.scores) == 0 {
+				delete(ms.labelRankings[kind], label)
+			}
+		}
+	}
+	return nil
+}
+
+func (ms *MemoryStore) GetTopVideosForLabels(ctx context.Context, kind models.LabelKind, labels []string, start, stop int64) ([]models.Video, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	merged := newSortedSet()
+	for _, label := range labels {
+		if ranking, exists := ms.labelRankings[kind][label]; exists {
+			merged.unionMax(ranking)
+		}
+	}
+	return videosFromMembers(merged.revRange(start, stop), 1), nil
+}
+
+func (ms *MemoryStore) IncrementAudienceScores(ctx context.Context, videoID uuid.UUID, deltas map[models.Audience]float64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for audience, delta := range deltas {
+		ranking, exists := ms.audienceRanking[audience]
+		if !exists {
+			ranking = newSortedSet()
+			ms.audienceRanking[audience] = ranking
+		}
+		ranking.incrBy(videoID.String(), delta)
+	}
+	return nil
+}
+
+func (ms *MemoryStore) GetTopVideosForAudience(ctx context.Context, audience models.Audience, start, stop int64) ([]models.Video, int64, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	ranking, exists := ms.audienceRanking[audience]
+	if !exists {
+		return []models.Video{}, 0, nil
+	}
+	return videosFromMembers(ranking.revRange(start, stop), 1), int64(len(ranking.scores)), nil
+}
+
+// SetCreatorVideoScore aggregates creator scores the same way as RedisStore.
+func (ms *MemoryStore) SetCreatorVideoScore(ctx context.Context, creatorID string, videoID uuid.UUID, score float64, topK int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	videos, exists := ms.creatorVideos[creatorID]
+	if !exists {
+		videos = newSortedSet()
+		ms.creatorVideos[creatorID] = videos
+	}
+	previous := videos.scores[videoID.String()]
+	videos.add(videoID.String(), score)
+
+	if topK > 0 {
+		top := videos.revRange(0, int64(topK)-1)
+		var sum float64
+		for _, member := range top {
+			sum += member.score
+		}
+		ms.creatorRanking.add(creatorID, sum/float64(len(top)))
+		return nil
+	}
+	ms.creatorRanking.incrBy(creatorID, score-previous)
+	return nil
+}
+
+func (ms *MemoryStore) GetTopCreators(ctx context.Context, start, stop int64) ([]models.CreatorScore, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	members := ms.creatorRanking.revRange(start, stop)
+	creators := make([]models.CreatorScore, 0, len(members))
+	for _, member := range members {
+		creators = append(creators, models.CreatorScore{CreatorID: member.member, Score: member.score})
+	}
+	return creators, nil
+}
+
+func (ms *MemoryStore) GetTopCreatorVideos(ctx context.Context, creatorID string, start, stop int64) ([]models.Video, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	videos, exists := ms.creatorVideos[creatorID]
+	if !exists {
+		return []models.Video{}, nil
+	}
+	return videosFromMembers(videos.revRange(start, stop), 1), nil
+}
+
+func videosFromMembers(members []scoredMember, scale float64) []models.Video {
+	videos := make([]models.Video, 0, len(members))
+	for _, member := range members {
+		videoID, err := uuid.Parse(member.member)
+		if err != nil {
+			continue
+		}
+		videos = append(videos, models.Video{ID: videoID, Score: member.score * scale})
+	}
+	return videos
+}
+
+func (ms *MemoryStore) CacheUserPreferences(ctx context.Context, userID string, preferences models.UserPreference, expiration time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	cached := cachedPreference{preferences: preferences}
+	cached.preferences.Categories = append([]string(nil), preferences.Categories...)
+	if expiration > 0 {
+		cached.expiresAt = time.Now().Add(expiration)
+	}
+	ms.preferenceCache[userID] = cached
+	return nil
+}
+
+func (ms *MemoryStore) GetCachedUserPreferences(ctx context.Context, userID string) (*models.UserPreference, error) {
+	ms.mu.RLock()
+	cached, exists := ms.preferenceCache[userID]
+	ms.mu.RUnlock()
+
+	if !exists {
+		return nil, nil // Cache miss
+	}
+	if !cached.expiresAt.IsZero() && time.Now().After(cached.expiresAt) {
+		ms.mu.Lock()
+		if current, ok := ms.preferenceCache[userID]; ok && current.expiresAt.Equal(cached.expiresAt) {
+			delete(ms.preferenceCache, userID)
+		}
+		ms.mu.Unlock()
+		return nil, nil
+	}
+
+	preferences := cached.preferences
+	preferences.Categories = append([]string(nil), cached.preferences.Categories...)
+	return &preferences, nil
+}
+
+func (ms *MemoryStore) DeleteCachedUserPreferences(ctx context.Context, userID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.preferenceCache, userID)
+	return nil
+}
+
+// claimSweepInterval bounds how often expired event claims are swept from memory.
+const claimSweepInterval = time.Minute
+
+func (ms *MemoryStore) ClaimEvent(ctx context.Context, eventID string, ttl time.Duration) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(ms.lastClaimSweep) > claimSweepInterval {
+		for id, expiresAt := range ms.claimedEvents {
+			if now.After(expiresAt) {
+				delete(ms.claimedEvents, id)
+			}
+		}
+		ms.lastClaimSweep = now
+	}
+
+	if expiresAt, exists := ms.claimedEvents[eventID]; exists && now.Before(expiresAt) {
+		return false, nil
+	}
+	ms.claimedEvents[eventID] = now.Add(ttl)
+	return true, nil
+}
+
+func (ms *MemoryStore) ConfirmEvent(ctx context.Context, eventID string, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.claimedEvents[eventID] = time.Now().Add(ttl)
+	return nil
+}
+
+func (ms *MemoryStore) ReleaseEvent(ctx context.Context, eventID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.claimedEvents, eventID)
+	return nil
+}
+
+func (ms *MemoryStore) Close() error {
+	return nil
+}
+
+// sortedSet is a minimal stand-in for a Redis sorted set. It is not safe for
+// concurrent use; callers must hold MemoryStore.mu.
+type sortedSet struct {
+	scores map[string]float64
+}
+
+type scoredMember struct {
+	member string
+	score  float64
+}
+
+func newSortedSet() *sortedSet {
+	return &sortedSet{scores: make(map[string]float64)}
+}
+
+func (s *sortedSet) add(member string, score float64) {
+	s.scores[member] = score
+}
+
+func (s *sortedSet) incrBy(member string, delta float64) float64 {
+	s.scores[member] += delta
+	return s.scores[member]
+}
+
+// union adds every score in other to s, like ZUNIONSTORE with the default SUM aggregate.
+func (s *sortedSet) union(other *sortedSet) {
+	for member, score := range other.scores {
+		s.scores[member] += score
+	}
+}
+
+// unionMax merges other into s keeping the higher score of members in both, like
+// ZUNIONSTORE with AGGREGATE MAX.
+func (s *sortedSet) unionMax(other *sortedSet) {
+	for member, score := range other.scores {
+		if current, exists := s.scores[member]; !exists || score > current {
+			s.scores[member] = score
+		}
+	}
+}
+
+func (s *sortedSet) remove(member string) {
+	delete(s.scores, member)
+}
+
+func (s *sortedSet) scale(factor float64) {
+	for member, score := range s.scores {
+		s.scores[member] = score * factor
+	}
+}
+
+// revRank returns the member's 0-based position in revRange order, like ZREVRANK.
+func (s *sortedSet) revRank(member string) (int64, bool) {
+	score, exists := s.scores[member]
+	if !exists {
+		return 0, false
+	}
+	var rank int64
+	for other, otherScore := range s.scores {
+		if otherScore > score || (otherScore == score && other > member) {
+			rank++
+		}
+	}
+	return rank, true
+}
+
+// revRange returns members ordered from highest to lowest score, using the same
+// inclusive and negative index semantics as ZREVRANGE. Ties are broken by
+// reverse lexicographic member order, as in Redis.
+func (s *sortedSet) revRange(start, stop int64) []scoredMember {
+	members := make([]scoredMember, 0, len(s.scores))
+	for member, score := range s.scores {
+		members = append(members, scoredMember{member: member, score: score})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].score != members[j].score {
+			return members[i].score > members[j].score
+		}
+		return members[i].member > members[j].member
+	})
+
+	size := int64(len(members))
+	if start < 0 {
+		start += size
+	}
+	if stop < 0 {
+		stop += size
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= size {
+		stop = size - 1
+	}
+	if start > stop || start >= size {
+		return []scoredMember{}
+	}
+	return members[start : stop+1]
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"realtime-ranking/models"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testSortedSet() *sortedSet {
+	set := newSortedSet()
+	set.add("a", 1)
+	set.add("b", 5)
+	set.add("c", 3)
+	set.add("d", 3)
+	set.add("e", 0)
+	return set
+}
+
+func members(scored []scoredMember) []string {
+	names := make([]string, len(scored))
+	for i, member := range scored {
+		names[i] = member.member
+	}
+	return names
+}
+
+func TestSortedSetRevRange(t *testing.T) {
+	// Ordered like ZREVRANGE: b(5) d(3) c(3) a(1) e(0); ties in reverse member order.
+	tests := []struct {
+		name        string
+		start, stop int64
+		want        []string
+	}{
+		{"everything", 0, -1, []string{"b", "d", "c", "a", "e"}},
+		{"first two", 0, 1, []string{"b", "d"}},
+		{"ties in reverse member order", 1, 2, []string{"d", "c"}},
+		{"single", 3, 3, []string{"a"}},
+		{"negative stop", 0, -2, []string{"b", "d", "c", "a"}},
+		{"negative start and stop", -2, -1, []string{"a", "e"}},
+		{"negative start before the beginning", -10, 1, []string{"b", "d"}},
+		{"stop past the end", 2, 100, []string{"c", "a", "e"}},
+		{"start past the end", 5, 10, []string{}},
+		{"start after stop", 3, 1, []string{}},
+		{"stop before the beginning", 0, -10, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := members(testSortedSet().revRange(tt.start, tt.stop))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("revRange(%d, %d) = %v, want %v", tt.start, tt.stop, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedSetRevRangeEmpty(t *testing.T) {
+	if got := newSortedSet().revRange(0, -1); len(got) != 0 {
+		t.Errorf("revRange on an empty set = %v, want none", got)
+	}
+}
+
+func TestCachedUserPreferencesExpire(t *testing.T) {
+	ctx := context.Background()
+	ms := NewMemoryStore(0)
+	preferences := models.UserPreference{UserID: "user-1", Categories: []string{"music"}}
+
+	if err := ms.CacheUserPreferences(ctx, "user-1", preferences, 50*time.Millisecond); err != nil {
+		t.Fatalf("CacheUserPreferences: %v", err)
+	}
+	cached, err := ms.GetCachedUserPreferences(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetCachedUserPreferences: %v", err)
+	}
+	if cached == nil || !reflect.DeepEqual(cached.Categories, preferences.Categories) {
+		t.Fatalf("GetCachedUserPreferences before expiry = %+v, want %+v", cached, preferences)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	cached, err = ms.GetCachedUserPreferences(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetCachedUserPreferences: %v", err)
+	}
+	if cached != nil {
+		t.Errorf("GetCachedUserPreferences after expiry = %+v, want a cache miss", cached)
+	}
+}
+
+func TestCachedUserPreferencesWithoutExpiration(t *testing.T) {
+	ctx := context.Background()
+	ms := NewMemoryStore(0)
+	preferences := models.UserPreference{UserID: "user-1", Categories: []string{"music"}}
+
+	if err := ms.CacheUserPreferences(ctx, "user-1", preferences, 0); err != nil {
+		t.Fatalf("CacheUserPreferences: %v", err)
+	}
+	// The cache must hold its own copy of the categories.
+	preferences.Categories[0] = "news"
+
+	cached, err := ms.GetCachedUserPreferences(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetCachedUserPreferences: %v", err)
+	}
+	if cached == nil || !reflect.DeepEqual(cached.Categories, []string{"music"}) {
+		t.Fatalf("GetCachedUserPreferences = %+v, want categories [music]", cached)
+	}
+
+	if err := ms.DeleteCachedUserPreferences(ctx, "user-1"); err != nil {
+		t.Fatalf("DeleteCachedUserPreferences: %v", err)
+	}
+	if cached, _ := ms.GetCachedUserPreferences(ctx, "user-1"); cached != nil {
+		t.Errorf("GetCachedUserPreferences after delete = %+v, want a cache miss", cached)
+	}
+}
+
+func TestConcurrentIncrements(t *testing.T) {
+	ctx := context.Background()
+	ms := NewMemoryStore(0)
+	videoID := uuid.New()
+
+	const goroutines, increments = 8, 250
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				interaction := &models.UserVideoInteraction{UserID: "user-1", VideoID: videoID, Views: 1, WatchTime: 2}
+				if err := ms.UpdateUserVideoInteraction(ctx, interaction); err != nil {
+					t.Errorf("UpdateUserVideoInteraction: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	const total = goroutines * increments
+	interactions, err := ms.GetUserVideoInteractions(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetUserVideoInteractions: %v", err)
+	}
+	if len(interactions) != 1 || interactions[0].Views != total || interactions[0].WatchTime != 2*total {
+		t.Errorf("interactions = %+v, want one with %d views and %d seconds watched", interactions, total, 2*total)
+	}
+}
+
+func TestConcurrentVideoIncrements(t *testing.T) {
+	ctx := context.Background()
+	ms := NewMemoryStore(0)
+	video := &models.Video{ID: uuid.New(), Title: "concurrent"}
+	if err := ms.CreateVideo(ctx, video); err != nil {
+		t.Fatalf("CreateVideo: %v", err)
+	}
+
+	// The score is recomputed from the counters, so it ends up consistent with them
+	// however the increments interleave.
+	score := func(video models.Video) float64 { return 0.5 * float64(video.Likes) }
+
+	const goroutines, increments = 8, 250
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				delta := models.VideoDelta{VideoID: video.ID, Views: 1, Likes: 1, WatchTime: 2}
+				if _, err := ms.IncrementVideoCounters(ctx, delta, score); err != nil {
+					t.Errorf("IncrementVideoCounters: %v", err)
+					return
+				}
+				if _, err := ms.IncrementVideoScore(ctx, video.ID, 0.5); err != nil {
+					t.Errorf("IncrementVideoScore: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	const total = goroutines * increments
+	stored, err := ms.GetVideo(ctx, video.ID)
+	if err != nil {
+		t.Fatalf("GetVideo: %v", err)
+	}
+	if stored.Views != total || stored.Likes != total || stored.WatchTime != 2*total || stored.Score != 0.5*total {
+		t.Errorf("counters = views %d, likes %d, watch time %d, score %v; want %d, %d, %d, %v",
+			stored.Views, stored.Likes, stored.WatchTime, stored.Score, total, total, 2*total, 0.5*total)
+	}
+
+	top, err := ms.GetTopVideos(ctx, models.RankingModeAllTime, 0, -1)
+	if err != nil {
+		t.Fatalf("GetTopVideos: %v", err)
+	}
+	if len(top) != 1 || top[0].ID != video.ID || top[0].Score != 0.5*total {
+		t.Errorf("GetTopVideos = %+v, want %s with score %v", top, video.ID, 0.5*total)
+	}
+}
+
+func TestSortedSetRevRank(t *testing.T) {
+	tests := []struct {
+		member     string
+		wantRank   int64
+		wantRanked bool
+	}{
+		{"b", 0, true},
+		{"d", 1, true},
+		{"c", 2, true},
+		{"a", 3, true},
+		{"e", 4, true},
+		{"z", 0, false},
+	}
+	set := testSortedSet()
+	for _, tt := range tests {
+		rank, ranked := set.revRank(tt.member)
+		if rank != tt.wantRank || ranked != tt.wantRanked {
+			t.Errorf("revRank(%q) = %d, %v; want %d, %v", tt.member, rank, ranked, tt.wantRank, tt.wantRanked)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"realtime-ranking/models"
+	"time"
+)
+
+func (ms *MemoryStore) EnqueueOutboxMessage(ctx context.Context, message models.OutboxMessage) error {
+	ms.outboxMu.Lock()
+	defer ms.outboxMu.Unlock()
+
+	ms.outboxSeq++
+	message.ID = ms.outboxSeq
+	message.Attempts = 0
+	message.CreatedAt = time.Now().UTC()
+	message.Payload = append([]byte(nil), message.Payload...)
+	ms.outbox = append(ms.outbox, message)
+	return nil
+}
+
+// PublishOutboxMessages holds the outbox lock for the duration of publish, which
+// serializes relays in the same way as the row locks taken by PostgresStore.
+func (ms *MemoryStore) PublishOutboxMessages(ctx context.Context, limit int, publish func(ctx context.Context, messages []models.OutboxMessage) error) (int, error) {
+	ms.outboxMu.Lock()
+	defer ms.outboxMu.Unlock()
+
+	count := len(ms.outbox)
+	if count > limit {
+		count = limit
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	messages := append([]models.OutboxMessage(nil), ms.outbox[:count]...)
+	if err := publish(ctx, messages); err != nil {
+		for i := 0; i < count; i++ {
+			ms.outbox[i].Attempts++
+		}
+		return 0, fmt.Errorf("error publishing outbox messages: %w", err)
+	}
+	ms.outbox = ms.outbox[count:]
+	return count, nil
+}
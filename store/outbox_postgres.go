@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"realtime-ranking/models"
+)
+
+func (ps *PostgresStore) EnqueueOutboxMessage(ctx context.Context, message models.OutboxMessage) error {
+	_, err := ps.pool.Exec(ctx,
+		"INSERT INTO event_outbox (topic, message_key, payload, content_type) VALUES ($1, $2, $3, $4)",
+		message.Topic, message.Key, message.Payload, message.ContentType)
+	if err != nil {
+		return fmt.Errorf("error enqueueing outbox message: %w", err)
+	}
+	return nil
+}
+
+// PublishOutboxMessages locks the pending rows with SKIP LOCKED for the duration of
+// publish, so several relays can run against the same table.
+func (ps *PostgresStore) PublishOutboxMessages(ctx context.Context, limit int, publish func(ctx context.Context, messages []models.OutboxMessage) error) (int, error) {
+	tx, err := ps.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error starting outbox transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		"SELECT id, topic, message_key, payload, content_type, attempts, created_at FROM event_outbox ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED",
+		limit)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching outbox messages: %w", err)
+	}
+	var messages []models.OutboxMessage
+	var ids []int64
+	for rows.Next() {
+		var message models.OutboxMessage
+		if err := rows.Scan(&message.ID, &message.Topic, &message.Key, &message.Payload, &message.ContentType, &message.Attempts, &message.CreatedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning outbox message: %w", err)
+		}
+		messages = append(messages, message)
+		ids = append(ids, message.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating outbox messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	publishErr := publish(ctx, messages)
+	if publishErr != nil {
+		_, err = tx.Exec(ctx,
+			"UPDATE event_outbox SET attempts = attempts + 1, last_error = $2 WHERE id = ANY($1)",
+			ids, publishErr.Error())
+	} else {
+		_, err = tx.Exec(ctx, "DELETE FROM event_outbox WHERE id = ANY($1)", ids)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error updating outbox messages: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing outbox transaction: %w", err)
+	}
+	if publishErr != nil {
+		return 0, fmt.Errorf("error publishing outbox messages: %w", publishErr)
+	}
+	return len(messages), nil
+}
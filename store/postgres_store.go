@@ -12,6 +12,81 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// videoColumns lists the videos columns in the order scanVideo expects them.
+const videoColumns = "id, title, data, score, views, likes, comments, shares, watch_time, scoring_version, creator_id, language, duration_seconds, created_at, updated_at"
+
+func scanVideo(row pgx.Row, video *models.Video) error {
+	return row.Scan(&video.ID, &video.Title, &video.Data, &video.Score, &video.Views, &video.Likes, &video.Comments, &video.Shares, &video.WatchTime, &video.ScoringVersion, &video.CreatorID, &video.Language, &video.DurationSeconds, &video.CreatedAt, &video.UpdatedAt)
+}
+
+// queryer is implemented by both the pool and transactions.
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// loadVideoLabels fills in the categories and tags of videos with one query.
+func loadVideoLabels(ctx context.Context, q queryer, videos []*models.Video) error {
+	if len(videos) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*models.Video, len(videos))
+	ids := make([]string, 0, len(videos))
+	for _, video := range videos {
+		video.Categories = []string{}
+		video.Tags = []string{}
+		byID[video.ID] = video
+		ids = append(ids, video.ID.String())
+	}
+
+	rows, err := q.Query(ctx,
+		`SELECT video_id, 'category', category, position FROM video_categories WHERE video_id = ANY($1::uuid[])
+         UNION ALL
+         SELECT video_id, 'tag', tag, position FROM video_tags WHERE video_id = ANY($1::uuid[])
+         ORDER BY 4`, ids)
+	if err != nil {
+		return fmt.Errorf("error querying video labels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var videoID uuid.UUID
+		var kind, label string
+		var position int
+		if err := rows.Scan(&videoID, &kind, &label, &position); err != nil {
+			return fmt.Errorf("error scanning video label row: %w", err)
+		}
+		video := byID[videoID]
+		if video == nil {
+			continue
+		}
+		if kind == "category" {
+			video.Categories = append(video.Categories, label)
+		} else {
+			video.Tags = append(video.Tags, label)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over video label rows: %w", err)
+	}
+	return nil
+}
+
+// replaceVideoLabels overwrites the categories or tags of a video.
+func replaceVideoLabels(ctx context.Context, tx pgx.Tx, table, column string, videoID uuid.UUID, labels []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM "+table+" WHERE video_id = $1", videoID); err != nil {
+		return fmt.Errorf("error deleting from %s: %w", table, err)
+	}
+	for position, label := range labels {
+		_, err := tx.Exec(ctx,
+			"INSERT INTO "+table+" (video_id, "+column+", position) VALUES ($1, $2, $3)",
+			videoID, label, position)
+		if err != nil {
+			return fmt.Errorf("error inserting into %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
 type PostgresStore struct {
 	pool *pgxpool.Pool
 }
@@ -20,43 +95,219 @@ func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
 	return &PostgresStore{pool: pool}
 }
 
+// CreateVideo inserts the video together with its categories and tags in one
+// transaction.
 func (ps *PostgresStore) CreateVideo(ctx context.Context, video *models.Video) error {
 	video.CreatedAt = time.Now().UTC()
 	video.UpdatedAt = time.Now().UTC()
-	_, err := ps.pool.Exec(ctx,
-		"INSERT INTO videos (id, title, data, score, views, likes, comments, shares, watch_time, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
-		video.ID, video.Title, video.Data, video.Score, video.Views, video.Likes, video.Comments, video.Shares, video.WatchTime, video.CreatedAt, video.UpdatedAt)
+
+	tx, err := ps.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting video transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		"INSERT INTO videos (id, title, data, score, views, likes, comments, shares, watch_time, creator_id, language, duration_seconds, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+		video.ID, video.Title, video.Data, video.Score, video.Views, video.Likes, video.Comments, video.Shares, video.WatchTime, video.CreatorID, video.Language, video.DurationSeconds, video.CreatedAt, video.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("error creating video: %w", err)
 	}
+	if err := replaceVideoLabels(ctx, tx, "video_categories", "category", video.ID, video.Categories); err != nil {
+		return err
+	}
+	if err := replaceVideoLabels(ctx, tx, "video_tags", "tag", video.ID, video.Tags); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing video transaction: %w", err)
+	}
 	return nil
 }
 
-func (ps *PostgresStore) UpdateVideo(ctx context.Context, video *models.Video) error {
-	video.UpdatedAt = time.Now().UTC()
-	_, err := ps.pool.Exec(ctx,
-		"UPDATE videos SET title = $2, data = $3, score = $4, views = $5, likes = $6, comments = $7, shares = $8, watch_time = $9, updated_at = $10 WHERE id = $1",
-		video.ID, video.Title, video.Data, video.Score, video.Views, video.Likes, video.Comments, video.Shares, video.WatchTime, video.UpdatedAt)
+// UpdateVideoMetadata changes only the metadata fields set in update; counters and
+// score are left as they are.
+func (ps *PostgresStore) UpdateVideoMetadata(ctx context.Context, videoID uuid.UUID, update models.VideoMetadataUpdate) (*models.Video, error) {
+	tx, err := ps.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("error updating video: %w", err)
+		return nil, fmt.Errorf("error starting video transaction: %w", err)
 	}
-	return nil
+	defer tx.Rollback(ctx)
+
+	video := &models.Video{}
+	row := tx.QueryRow(ctx,
+		`UPDATE videos SET
+            title = COALESCE($2, title),
+            data = COALESCE($3, data),
+            language = COALESCE($4, language),
+            duration_seconds = COALESCE($5, duration_seconds),
+            updated_at = $6
+         WHERE id = $1
+         RETURNING `+videoColumns,
+		videoID, update.Title, update.Data, update.Language, update.DurationSeconds, time.Now().UTC())
+	if err := scanVideo(row, video); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+		}
+		return nil, fmt.Errorf("error updating video metadata: %w", err)
+	}
+	if update.Categories != nil {
+		if err := replaceVideoLabels(ctx, tx, "video_categories", "category", videoID, *update.Categories); err != nil {
+			return nil, err
+		}
+	}
+	if update.Tags != nil {
+		if err := replaceVideoLabels(ctx, tx, "video_tags", "tag", videoID, *update.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadVideoLabels(ctx, tx, []*models.Video{video}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing video transaction: %w", err)
+	}
+	return video, nil
 }
 
 func (ps *PostgresStore) GetVideo(ctx context.Context, videoID uuid.UUID) (*models.Video, error) {
 	video := &models.Video{}
-	err := ps.pool.QueryRow(ctx, "SELECT id, title, data, score, views, likes, comments, shares, watch_time, created_at, updated_at FROM videos WHERE id = $1", videoID).Scan(&video.ID, &video.Title, &video.Data, &video.Score, &video.Views, &video.Likes, &video.Comments, &video.Shares, &video.WatchTime, &video.CreatedAt, &video.UpdatedAt)
+	err := scanVideo(ps.pool.QueryRow(ctx, "SELECT "+videoColumns+" FROM videos WHERE id = $1", videoID), video)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("video not found: %w", err)
+			return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
 		}
 		return nil, fmt.Errorf("error getting video: %w", err)
 	}
+	if err := loadVideoLabels(ctx, ps.pool, []*models.Video{video}); err != nil {
+		return nil, err
+	}
 	return video, nil
 }
 
-func (ps *PostgresStore) Close() {
+// IncrementVideoCounters adds delta to the video's counters and rewrites its score in
+// one transaction. The row stays locked from the increment until the score is written,
+// so concurrent events for the same video never overwrite each other. The returned
+// video includes its categories and tags, which per-label leaderboards need.
+func (ps *PostgresStore) IncrementVideoCounters(ctx context.Context, delta models.VideoDelta, score ScoreFunc) (*models.Video, error) {
+	tx, err := ps.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting video counters transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	video := &models.Video{}
+	now := time.Now().UTC()
+	row := tx.QueryRow(ctx,
+		`UPDATE videos SET
+            views = views + $2,
+            likes = likes + $3,
+            comments = comments + $4,
+            shares = shares + $5,
+            watch_time = watch_time + $6,
+            updated_at = $7
+         WHERE id = $1
+         RETURNING `+videoColumns,
+		delta.VideoID, delta.Views, delta.Likes, delta.Comments, delta.Shares, delta.WatchTime, now)
+	if err := scanVideo(row, video); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, delta.VideoID)
+		}
+		return nil, fmt.Errorf("error incrementing video counters: %w", err)
+	}
+
+	video.Score = score(*video)
+	video.ScoringVersion = delta.ScoringVersion
+	_, err = tx.Exec(ctx,
+		"UPDATE videos SET score = $2, scoring_version = $3 WHERE id = $1",
+		video.ID, video.Score, video.ScoringVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error setting video score: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing video counters transaction: %w", err)
+	}
+
+	if err := loadVideoLabels(ctx, ps.pool, []*models.Video{video}); err != nil {
+		return nil, err
+	}
+	return video, nil
+}
+
+// GetVideos loads several videos, with their categories and tags, in two queries. The
+// result follows the order of videoIDs; IDs that do not exist are skipped.
+func (ps *PostgresStore) GetVideos(ctx context.Context, videoIDs []uuid.UUID) ([]models.Video, error) {
+	if len(videoIDs) == 0 {
+		return []models.Video{}, nil
+	}
+
+	ids := make([]string, len(videoIDs))
+	for i, videoID := range videoIDs {
+		ids[i] = videoID.String()
+	}
+
+	rows, err := ps.pool.Query(ctx,
+		"SELECT "+videoColumns+" FROM videos WHERE id = ANY($1::uuid[])", ids)
+	if err != nil {
+		return nil, fmt.Errorf("error querying videos: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[uuid.UUID]*models.Video, len(videoIDs))
+	loaded := make([]*models.Video, 0, len(videoIDs))
+	for rows.Next() {
+		video := &models.Video{}
+		if err := scanVideo(rows, video); err != nil {
+			return nil, fmt.Errorf("error scanning video row: %w", err)
+		}
+		byID[video.ID] = video
+		loaded = append(loaded, video)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over video rows: %w", err)
+	}
+	rows.Close()
+
+	if err := loadVideoLabels(ctx, ps.pool, loaded); err != nil {
+		return nil, err
+	}
+
+	videos := make([]models.Video, 0, len(byID))
+	for _, videoID := range videoIDs {
+		if video, ok := byID[videoID]; ok {
+			videos = append(videos, *video)
+		}
+	}
+	return videos, nil
+}
+
+func (ps *PostgresStore) ListVideosScoredWithOtherVersions(ctx context.Context, scoringVersion string, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := ps.pool.Query(ctx,
+		"SELECT id FROM videos WHERE scoring_version <> $1 AND id > $2 ORDER BY id LIMIT $3",
+		scoringVersion, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying videos by scoring version: %w", err)
+	}
+	defer rows.Close()
+
+	var videoIDs []uuid.UUID
+	for rows.Next() {
+		var videoID uuid.UUID
+		if err := rows.Scan(&videoID); err != nil {
+			return nil, fmt.Errorf("error scanning video ID: %w", err)
+		}
+		videoIDs = append(videoIDs, videoID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over video IDs: %w", err)
+	}
+	return videoIDs, nil
+}
+
+func (ps *PostgresStore) Close() error {
 	ps.pool.Close()
+	return nil
 }
 
 func (ps *PostgresStore) GetUserVideoInteractions(ctx context.Context, userID string) ([]models.UserVideoInteraction, error) {
@@ -115,9 +366,9 @@ func (ps *PostgresStore) UpdateUserVideoInteraction(ctx context.Context, interac
          DO UPDATE SET
             last_viewed = $3,
             views = user_video_interactions.views + $4,
-            likes = $5,
-            comments = $6,
-            shares = $7,
+            likes = user_video_interactions.likes + $5,
+            comments = user_video_interactions.comments + $6,
+            shares = user_video_interactions.shares + $7,
             watch_time = user_video_interactions.watch_time + $8`,
 		interaction.UserID, interaction.VideoID, interaction.LastViewed, interaction.Views, interaction.Likes, interaction.Comments, interaction.Shares, interaction.WatchTime)
 	if err != nil {
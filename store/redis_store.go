@@ -6,17 +6,106 @@ import (
 	"fmt"
 	"log"
 	"realtime-ranking/models"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 )
 
+const (
+	allTimeRankingKey = "video_ranking"
+	hotRankingKey     = "video_ranking:hot"
+	hotEpochKey       = "video_ranking:hot:epoch"
+	bucketKeyPrefix   = "video_ranking:bucket"
+	windowKeyPrefix   = "video_ranking:window"
+	// Per-label leaderboards live at video_ranking:<kind>:<label>.
+	labelKeyPrefix      = "video_ranking"
+	labelUnionKeyPrefix = "video_ranking:union"
+	creatorRankingKey   = "creator_ranking"
+	// Audience leaderboards live at video_ranking:<kind>:<name>, next to label ones.
+	audienceKeyPrefix = "video_ranking"
+	// Each creator's videos are ranked at video_ranking:creator:<creator ID>.
+	creatorVideosKeyPrefix = "video_ranking:creator"
+)
+
+// labelUnionCacheTTL bounds how long a merged multi-label leaderboard is reused before
+// it is rebuilt.
+const labelUnionCacheTTL = 5 * time.Second
+
+// hotScoreScript adds a forward-decayed increment to the hot ranking, rebasing the set
+// and its epoch first if the weight would grow past 2^hotRebaseExponent.
+//
+// KEYS[1] hot ranking, KEYS[2] epoch (unix seconds)
+// ARGV[1] member, ARGV[2] value, ARGV[3] event time (unix seconds), ARGV[4] half-life (seconds), ARGV[5] rebase exponent
+var hotScoreScript = redis.NewScript(`
+local at = tonumber(ARGV[3])
+local halfLife = tonumber(ARGV[4])
+local epoch = tonumber(redis.call('GET', KEYS[2]))
+if not epoch then
+	epoch = at
+	redis.call('SET', KEYS[2], tostring(epoch))
+end
+local exponent = (at - epoch) / halfLife
+if exponent > tonumber(ARGV[5]) then
+	local shift = math.floor(exponent)
+	redis.call('ZUNIONSTORE', KEYS[1], 1, KEYS[1], 'WEIGHTS', tostring(2 ^ -shift))
+	epoch = epoch + shift * halfLife
+	redis.call('SET', KEYS[2], tostring(epoch))
+	exponent = exponent - shift
+end
+return redis.call('ZINCRBY', KEYS[1], tostring(tonumber(ARGV[2]) * 2 ^ exponent), ARGV[1])
+`)
+
+// creatorScoreScript sets a video's score among its creator's videos and updates the
+// creator's aggregate score. With a positive top-k the aggregate is recomputed as the
+// mean of the creator's top-k video scores; otherwise the change in the video's score
+// is added to the creator's sum.
+//
+// KEYS[1] creator's videos, KEYS[2] creator ranking
+// ARGV[1] video, ARGV[2] score, ARGV[3] creator, ARGV[4] top-k
+var creatorScoreScript = redis.NewScript(`
+local previous = tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1])) or 0
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+local topK = tonumber(ARGV[4])
+if topK > 0 then
+	local top = redis.call('ZREVRANGE', KEYS[1], 0, topK - 1, 'WITHSCORES')
+	local sum = 0
+	for i = 2, #top, 2 do
+		sum = sum + tonumber(top[i])
+	end
+	return redis.call('ZADD', KEYS[2], tostring(sum / (#top / 2)), ARGV[3])
+end
+return redis.call('ZINCRBY', KEYS[2], tostring(tonumber(ARGV[2]) - previous), ARGV[3])
+`)
+
+// unionRangeScript serves a leaderboard merged from several sorted sets, such as the
+// buckets of a window, rebuilding the merged set with ZUNIONSTORE only when the cached
+// copy has expired.
+//
+// KEYS[1] merged set, KEYS[2..] sources
+// ARGV[1] cache TTL (milliseconds), ARGV[2] start, ARGV[3] stop, ARGV[4] aggregate (SUM or MAX)
+var unionRangeScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	local args = {KEYS[1], #KEYS - 1}
+	for i = 2, #KEYS do
+		args[#args + 1] = KEYS[i]
+	end
+	args[#args + 1] = 'AGGREGATE'
+	args[#args + 1] = ARGV[4]
+	redis.call('ZUNIONSTORE', unpack(args))
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return redis.call('ZREVRANGE', KEYS[1], ARGV[2], ARGV[3], 'WITHSCORES')
+`)
+
 type RedisStore struct {
-	client *redis.Client
+	client      *redis.Client
+	hotHalfLife time.Duration
 }
 
-func NewRedisStore(redisURL string) (*RedisStore, error) {
+func NewRedisStore(redisURL string, hotHalfLife time.Duration) (*RedisStore, error) {
 	options, err := redis.ParseURL(redisURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
@@ -29,37 +118,296 @@ func NewRedisStore(redisURL string) (*RedisStore, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
-	return &RedisStore{client: client}, nil
+	if hotHalfLife <= 0 {
+		hotHalfLife = DefaultHotHalfLife
+	}
+	return &RedisStore{client: client, hotHalfLife: hotHalfLife}, nil
 }
 
 func (rs *RedisStore) UpdateVideoScore(ctx context.Context, videoID uuid.UUID, score float64) error {
-	return rs.client.ZAdd(ctx, "video_ranking", &redis.Z{
+	return rs.client.ZAdd(ctx, allTimeRankingKey, &redis.Z{
 		Score:  score,
 		Member: videoID.String(),
 	}).Err()
 }
 
-func (rs *RedisStore) GetTopVideos(ctx context.Context, start, stop int64) ([]models.Video, error) {
-	results, err := rs.client.ZRevRange(ctx, "video_ranking", start, stop).Result()
+func (rs *RedisStore) IncrementVideoScore(ctx context.Context, videoID uuid.UUID, delta float64) (float64, error) {
+	score, err := rs.client.ZIncrBy(ctx, allTimeRankingKey, delta, videoID.String()).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment video score in redis: %w", err)
+	}
+	return score, nil
+}
+
+func (rs *RedisStore) IncrementHotScore(ctx context.Context, videoID uuid.UUID, delta float64, at time.Time) error {
+	err := hotScoreScript.Run(ctx, rs.client,
+		[]string{hotRankingKey, hotEpochKey},
+		videoID.String(), delta, at.Unix(), rs.hotHalfLife.Seconds(), hotRebaseExponent).Err()
+	if err != nil {
+		return fmt.Errorf("failed to increment hot score in redis: %w", err)
+	}
+	return nil
+}
+
+func (rs *RedisStore) GetTopVideos(ctx context.Context, mode models.RankingMode, start, stop int64) ([]models.Video, error) {
+	if mode == models.RankingModeHot {
+		return rs.getTopHotVideos(ctx, start, stop)
+	}
+
+	results, err := rs.client.ZRevRangeWithScores(ctx, allTimeRankingKey, start, stop).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top videos from redis: %w", err)
 	}
+	return videosFromZ(results, 1), nil
+}
+
+func (rs *RedisStore) GetVideoRank(ctx context.Context, videoID uuid.UUID) (models.VideoRank, bool, error) {
+	pipe := rs.client.Pipeline()
+	rankCmd := pipe.ZRevRank(ctx, allTimeRankingKey, videoID.String())
+	scoreCmd := pipe.ZScore(ctx, allTimeRankingKey, videoID.String())
+	totalCmd := pipe.ZCard(ctx, allTimeRankingKey)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return models.VideoRank{}, false, fmt.Errorf("failed to get video rank from redis: %w", err)
+	}
+	if rankCmd.Err() == redis.Nil {
+		return models.VideoRank{}, false, nil
+	}
+	return models.VideoRank{
+		VideoID: videoID,
+		Rank:    rankCmd.Val(),
+		Score:   scoreCmd.Val(),
+		Total:   totalCmd.Val(),
+	}, true, nil
+}
+
+// getTopHotVideos reads the hot ranking and its epoch in one round trip and reports
+// each score decayed to the current time.
+func (rs *RedisStore) getTopHotVideos(ctx context.Context, start, stop int64) ([]models.Video, error) {
+	pipe := rs.client.Pipeline()
+	epochCmd := pipe.Get(ctx, hotEpochKey)
+	rangeCmd := pipe.ZRevRangeWithScores(ctx, hotRankingKey, start, stop)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get hot videos from redis: %w", err)
+	}
+
+	results, err := rangeCmd.Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hot videos from redis: %w", err)
+	}
+	epochSeconds, err := epochCmd.Float64()
+	if err != nil {
+		// No epoch means nothing has been scored yet.
+		return videosFromZ(results, 1), nil
+	}
+
+	epoch := time.Unix(0, int64(epochSeconds*float64(time.Second)))
+	return videosFromZ(results, hotDecayFactor(epoch, time.Now(), rs.hotHalfLife)), nil
+}
+
+// IncrementWindowScores adds delta to the current bucket of every bucket series and
+// refreshes the bucket's expiry, so old buckets age out on their own.
+func (rs *RedisStore) IncrementWindowScores(ctx context.Context, videoID uuid.UUID, delta float64, at time.Time) error {
+	pipe := rs.client.Pipeline()
+	for _, series := range allBucketSeries {
+		key := bucketKey(series, series.bucketStart(at))
+		pipe.ZIncrBy(ctx, key, delta, videoID.String())
+		pipe.Expire(ctx, key, series.ttl())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to increment window scores in redis: %w", err)
+	}
+	return nil
+}
+
+func (rs *RedisStore) GetTopVideosInWindow(ctx context.Context, window models.RankingWindow, start, stop int64) ([]models.Video, error) {
+	series, ok := windowSeries[window]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ranking window %q", window)
+	}
+
+	bucketStarts := series.bucketStarts(window.Duration(), time.Now())
+	keys := make([]string, 0, len(bucketStarts)+1)
+	keys = append(keys, fmt.Sprintf("%s:%s", windowKeyPrefix, window))
+	for _, bucketStart := range bucketStarts {
+		keys = append(keys, bucketKey(series, bucketStart))
+	}
+
+	raw, err := unionRangeScript.Run(ctx, rs.client, keys, windowCacheTTL.Milliseconds(), start, stop, "SUM").StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top videos for window %s from redis: %w", window, err)
+	}
+
+	results, err := zFromWithScores(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid top videos for window %s: %w", window, err)
+	}
+	return videosFromZ(results, 1), nil
+}
+
+func (rs *RedisStore) SetLabelScores(ctx context.Context, kind models.LabelKind, labels []string, videoID uuid.UUID, score float64) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	pipe := rs.client.Pipeline()
+	for _, label := range labels {
+		pipe.ZAdd(ctx, labelKey(kind, label), &redis.Z{Score: score, Member: videoID.String()})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set %s scores in redis: %w", kind, err)
+	}
+	return nil
+}
+
+func (rs *RedisStore) RemoveLabelScores(ctx context.Context, kind models.LabelKind, labels []string, videoID uuid.UUID) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	pipe := rs.client.Pipeline()
+	for _, label := range labels {
+		pipe.ZRem(ctx, labelKey(kind, label), videoID.String())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove %s scores in redis: %w", kind, err)
+	}
+	return nil
+}
+
+// GetTopVideosForLabels reads a single label's leaderboard directly. Several labels are
+// merged with the MAX aggregate, since a video has the same score in each of its
+// leaderboards, and the merged set is cached briefly like a window.
+func (rs *RedisStore) GetTopVideosForLabels(ctx context.Context, kind models.LabelKind, labels []string, start, stop int64) ([]models.Video, error) {
+	switch len(labels) {
+	case 0:
+		return []models.Video{}, nil
+	case 1:
+		results, err := rs.client.ZRevRangeWithScores(ctx, labelKey(kind, labels[0]), start, stop).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get top videos for %s %q from redis: %w", kind, labels[0], err)
+		}
+		return videosFromZ(results, 1), nil
+	}
+
+	sorted := append([]string{}, labels...)
+	sort.Strings(sorted)
+	keys := make([]string, 0, len(sorted)+1)
+	keys = append(keys, fmt.Sprintf("%s:%s:%q", labelUnionKeyPrefix, kind, sorted))
+	for _, label := range sorted {
+		keys = append(keys, labelKey(kind, label))
+	}
+
+	raw, err := unionRangeScript.Run(ctx, rs.client, keys, labelUnionCacheTTL.Milliseconds(), start, stop, "MAX").StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top videos for %ss %q from redis: %w", kind, sorted, err)
+	}
 
-	videos := make([]models.Video, len(results))
-	for i, videoIDStr := range results {
-		videoID, _ := uuid.Parse(videoIDStr)
-		score, err := rs.client.ZScore(ctx, "video_ranking", videoIDStr).Result() // `score` is already float64
+	results, err := zFromWithScores(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid top videos for %ss %q: %w", kind, sorted, err)
+	}
+	return videosFromZ(results, 1), nil
+}
+
+func (rs *RedisStore) IncrementAudienceScores(ctx context.Context, videoID uuid.UUID, deltas map[models.Audience]float64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	pipe := rs.client.Pipeline()
+	for audience, delta := range deltas {
+		pipe.ZIncrBy(ctx, audienceKey(audience), delta, videoID.String())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to increment audience scores in redis: %w", err)
+	}
+	return nil
+}
+
+func (rs *RedisStore) GetTopVideosForAudience(ctx context.Context, audience models.Audience, start, stop int64) ([]models.Video, int64, error) {
+	pipe := rs.client.Pipeline()
+	rangeCmd := pipe.ZRevRangeWithScores(ctx, audienceKey(audience), start, stop)
+	cardCmd := pipe.ZCard(ctx, audienceKey(audience))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, fmt.Errorf("failed to get top videos for %s from redis: %w", audience, err)
+	}
+	return videosFromZ(rangeCmd.Val(), 1), cardCmd.Val(), nil
+}
+
+func audienceKey(audience models.Audience) string {
+	return fmt.Sprintf("%s:%s:%s", audienceKeyPrefix, audience.Kind, audience.Name)
+}
+
+func (rs *RedisStore) SetCreatorVideoScore(ctx context.Context, creatorID string, videoID uuid.UUID, score float64, topK int) error {
+	err := creatorScoreScript.Run(ctx, rs.client,
+		[]string{creatorVideosKey(creatorID), creatorRankingKey},
+		videoID.String(), score, creatorID, topK).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set creator video score in redis: %w", err)
+	}
+	return nil
+}
+
+func (rs *RedisStore) GetTopCreators(ctx context.Context, start, stop int64) ([]models.CreatorScore, error) {
+	results, err := rs.client.ZRevRangeWithScores(ctx, creatorRankingKey, start, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top creators from redis: %w", err)
+	}
+	creators := make([]models.CreatorScore, 0, len(results))
+	for _, result := range results {
+		creatorID, _ := result.Member.(string)
+		creators = append(creators, models.CreatorScore{CreatorID: creatorID, Score: result.Score})
+	}
+	return creators, nil
+}
+
+func (rs *RedisStore) GetTopCreatorVideos(ctx context.Context, creatorID string, start, stop int64) ([]models.Video, error) {
+	results, err := rs.client.ZRevRangeWithScores(ctx, creatorVideosKey(creatorID), start, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top videos of creator %q from redis: %w", creatorID, err)
+	}
+	return videosFromZ(results, 1), nil
+}
+
+func creatorVideosKey(creatorID string) string {
+	return fmt.Sprintf("%s:%s", creatorVideosKeyPrefix, creatorID)
+}
+
+func labelKey(kind models.LabelKind, label string) string {
+	return fmt.Sprintf("%s:%s:%s", labelKeyPrefix, kind, label)
+}
+
+// zFromWithScores parses the flat member, score list returned by ZREVRANGE WITHSCORES
+// inside a script.
+func zFromWithScores(raw []string) ([]redis.Z, error) {
+	results := make([]redis.Z, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		score, err := strconv.ParseFloat(raw[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid score %q: %w", raw[i+1], err)
+		}
+		results = append(results, redis.Z{Member: raw[i], Score: score})
+	}
+	return results, nil
+}
+
+func bucketKey(series bucketSeries, bucketStart int64) string {
+	return fmt.Sprintf("%s:%d:%d", bucketKeyPrefix, int64(series.granularity/time.Second), bucketStart)
+}
+
+func videosFromZ(results []redis.Z, scale float64) []models.Video {
+	videos := make([]models.Video, 0, len(results))
+	for _, result := range results {
+		videoIDStr, _ := result.Member.(string)
+		videoID, err := uuid.Parse(videoIDStr)
 		if err != nil {
-			log.Printf("Error getting score for video %s from Redis: %v", videoIDStr, err)
+			log.Printf("Skipping invalid video ID %q in Redis ranking: %v", videoIDStr, err)
 			continue
 		}
 
-		videos[i] = models.Video{
+		videos = append(videos, models.Video{
 			ID:    videoID,
-			Score: score, // No need to use strconv.ParseFloat
-		}
+			Score: result.Score * scale,
+		})
 	}
-	return videos, nil
+	return videos
 }
 
 func (rs *RedisStore) Close() error {
@@ -91,6 +439,28 @@ func (rs *RedisStore) GetCachedUserPreferences(ctx context.Context, userID strin
 	return &preferences, nil
 }
 
+func (rs *RedisStore) ClaimEvent(ctx context.Context, eventID string, ttl time.Duration) (bool, error) {
+	claimed, err := rs.client.SetNX(ctx, fmt.Sprintf("event:processed:%s", eventID), 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim event in redis: %w", err)
+	}
+	return claimed, nil
+}
+
+func (rs *RedisStore) ConfirmEvent(ctx context.Context, eventID string, ttl time.Duration) error {
+	if err := rs.client.Set(ctx, fmt.Sprintf("event:processed:%s", eventID), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to confirm event in redis: %w", err)
+	}
+	return nil
+}
+
+func (rs *RedisStore) ReleaseEvent(ctx context.Context, eventID string) error {
+	if err := rs.client.Del(ctx, fmt.Sprintf("event:processed:%s", eventID)).Err(); err != nil {
+		return fmt.Errorf("failed to release event in redis: %w", err)
+	}
+	return nil
+}
+
 func (rs *RedisStore) DeleteCachedUserPreferences(ctx context.Context, userID string) error {
 	_, err := rs.client.Del(ctx, fmt.Sprintf("user:preferences:%s", userID)).Result()
 	if err != nil && err != redis.Nil {
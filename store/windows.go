@@ -0,0 +1,58 @@
+package store
+
+import (
+	"realtime-ranking/models"
+	"time"
+)
+
+// Windowed leaderboards are built from fixed-size time buckets. Every score increment is
+// added to the current bucket of each series, and a window is served by merging the
+// current, partly filled bucket with the window's worth of complete buckets before it.
+// A window therefore covers at least its full duration and at most one granularity
+// more, and slides with the granularity of its series.
+type bucketSeries struct {
+	granularity time.Duration
+	retention   time.Duration
+}
+
+var (
+	fiveMinuteBuckets = bucketSeries{granularity: 5 * time.Minute, retention: time.Hour}
+	hourlyBuckets     = bucketSeries{granularity: time.Hour, retention: 7 * 24 * time.Hour}
+
+	allBucketSeries = []bucketSeries{fiveMinuteBuckets, hourlyBuckets}
+)
+
+// windowSeries maps each supported window to the bucket series that serves it.
+var windowSeries = map[models.RankingWindow]bucketSeries{
+	models.RankingWindowHour: fiveMinuteBuckets,
+	models.RankingWindowDay:  hourlyBuckets,
+	models.RankingWindowWeek: hourlyBuckets,
+}
+
+// windowCacheTTL bounds how long a merged window is reused before it is rebuilt.
+const windowCacheTTL = 5 * time.Second
+
+// bucketStart returns the unix second at which the bucket containing at begins.
+func (s bucketSeries) bucketStart(at time.Time) int64 {
+	size := int64(s.granularity / time.Second)
+	return at.Unix() / size * size
+}
+
+// bucketStarts lists the buckets covering window, newest first: the current bucket
+// and window/granularity complete buckets before it.
+func (s bucketSeries) bucketStarts(window time.Duration, now time.Time) []int64 {
+	size := int64(s.granularity / time.Second)
+	count := int(window/s.granularity) + 1
+	current := s.bucketStart(now)
+
+	starts := make([]int64, count)
+	for i := range starts {
+		starts[i] = current - int64(i)*size
+	}
+	return starts
+}
+
+// ttl keeps a bucket alive for as long as any window may still read it.
+func (s bucketSeries) ttl() time.Duration {
+	return s.retention + s.granularity
+}
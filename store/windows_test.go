@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"realtime-ranking/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBucketStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		series bucketSeries
+		at     time.Time
+		want   time.Time
+	}{
+		{"five-minute boundary", fiveMinuteBuckets, time.Date(2024, 6, 1, 12, 5, 0, 0, time.UTC), time.Date(2024, 6, 1, 12, 5, 0, 0, time.UTC)},
+		{"inside a five-minute bucket", fiveMinuteBuckets, time.Date(2024, 6, 1, 12, 9, 59, 999, time.UTC), time.Date(2024, 6, 1, 12, 5, 0, 0, time.UTC)},
+		{"inside an hourly bucket", hourlyBuckets, time.Date(2024, 6, 1, 12, 59, 30, 0, time.UTC), time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.series.bucketStart(tt.at); got != tt.want.Unix() {
+				t.Errorf("bucketStart = %v, want %v", time.Unix(got, 0).UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketStarts(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 7, 30, 0, time.UTC)
+	unix := func(hour, minute int) int64 {
+		return time.Date(2024, 6, 1, hour, minute, 0, 0, time.UTC).Unix()
+	}
+	tests := []struct {
+		name   string
+		series bucketSeries
+		window time.Duration
+		want   []int64
+	}{
+		{"1h in five-minute buckets", fiveMinuteBuckets, time.Hour, []int64{
+			unix(12, 5), unix(12, 0), unix(11, 55), unix(11, 50), unix(11, 45), unix(11, 40),
+			unix(11, 35), unix(11, 30), unix(11, 25), unix(11, 20), unix(11, 15), unix(11, 10), unix(11, 5),
+		}},
+		{"3h in hourly buckets", hourlyBuckets, 3 * time.Hour, []int64{unix(12, 0), unix(11, 0), unix(10, 0), unix(9, 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.series.bucketStarts(tt.window, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bucketStarts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for window, series := range windowSeries {
+		starts := series.bucketStarts(window.Duration(), now)
+		// The oldest bucket must start no later than the window does, so that the whole
+		// window is covered.
+		oldest := time.Unix(starts[len(starts)-1], 0)
+		if windowStart := now.Add(-window.Duration()); oldest.After(windowStart) {
+			t.Errorf("window %s starts at %v but its oldest bucket starts at %v", window, windowStart, oldest)
+		}
+		if now.Sub(oldest) > series.ttl() {
+			t.Errorf("window %s reads a bucket from %v, older than its series keeps", window, oldest)
+		}
+	}
+}
+
+func TestWindowScores(t *testing.T) {
+	ctx := context.Background()
+	ms := NewMemoryStore(0)
+	now := time.Now()
+	recent, anHourAgo, earlierToday, lastWeek := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	increments := []struct {
+		videoID uuid.UUID
+		delta   float64
+		at      time.Time
+	}{
+		{recent, 2, now},
+		{recent, 3, now.Add(-time.Minute)},
+		// Inside the 1h window however the current bucket is aligned.
+		{anHourAgo, 7, now.Add(-59 * time.Minute)},
+		{earlierToday, 10, now.Add(-3 * time.Hour)},
+		{lastWeek, 20, now.Add(-3 * 24 * time.Hour)},
+		// Older than every window; dropped.
+		{lastWeek, 100, now.Add(-30 * 24 * time.Hour)},
+	}
+	for _, increment := range increments {
+		if err := ms.IncrementWindowScores(ctx, increment.videoID, increment.delta, increment.at); err != nil {
+			t.Fatalf("IncrementWindowScores: %v", err)
+		}
+	}
+
+	tests := []struct {
+		window models.RankingWindow
+		want   []models.Video
+	}{
+		{models.RankingWindowHour, []models.Video{{ID: anHourAgo, Score: 7}, {ID: recent, Score: 5}}},
+		{models.RankingWindowDay, []models.Video{{ID: earlierToday, Score: 10}, {ID: anHourAgo, Score: 7}, {ID: recent, Score: 5}}},
+		{models.RankingWindowWeek, []models.Video{{ID: lastWeek, Score: 20}, {ID: earlierToday, Score: 10}, {ID: anHourAgo, Score: 7}, {ID: recent, Score: 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.window), func(t *testing.T) {
+			got, err := ms.GetTopVideosInWindow(ctx, tt.window, 0, -1)
+			if err != nil {
+				t.Fatalf("GetTopVideosInWindow: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTopVideosInWindow = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ms.GetTopVideosInWindow(ctx, models.RankingWindow("2h"), 0, -1); err == nil {
+		t.Error("GetTopVideosInWindow accepted an unsupported window")
+	}
+}